@@ -14,6 +14,11 @@
 
 package yang
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
 var knownWords = map[string]string{
 	"Ietf": "IETF",
 }
@@ -28,6 +33,23 @@ func isASCIIDigit(c byte) bool {
 	return '0' <= c && c <= '9'
 }
 
+// Is r an ASCII letter, digit or underscore?
+func isIdentRune(r rune) bool {
+	return r < utf8.RuneSelf && (isASCIILower(byte(r)) || isASCIIDigit(byte(r)) || r == '_' || ('A' <= r && r <= 'Z'))
+}
+
+// SanitizeIdentifier returns s with each rune that may not appear in an
+// identifier, including any non-ASCII rune, replaced by an underscore.  The
+// result is always ASCII.
+func SanitizeIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isIdentRune(r) {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
 // CamelCase returns the CamelCased name.
 // If there is an interior underscore or dash followed by a lower case letter,
 // drop the underscore or dash and convert the letter to upper case.  There is a
@@ -35,19 +57,17 @@ func isASCIIDigit(c byte) bool {
 // remote we're prepared to pretend it's nonexistent - since the C++ generator
 // lowercases names, it's extremely unlikely to have two fields with different
 // capitalizations.  In short, _my_field_name_2 becomes XMyFieldName_2.
+// Runes that may not appear in an identifier, such as /, - and :, are first
+// converted to underscores (see SanitizeIdentifier).
 func CamelCase(s string) string {
-	fix := func(c byte) byte {
-		if c == '/' || c == '-' || c == ':' {
-			return '_'
-		}
-		return c
-	}
 	if s == "" {
 		return ""
 	}
+	// After sanitizing s is ASCII so it is safe to index bytes.
+	s = SanitizeIdentifier(s)
 	t := make([]byte, 0, 32)
 	i := 0
-	if fix(s[0]) == '_' {
+	if s[0] == '_' {
 		// Need a capital letter; drop the '_'.
 		t = append(t, 'X')
 		i++
@@ -57,7 +77,7 @@ func CamelCase(s string) string {
 	// That is, we process a word at a time, where words are marked by _ or
 	// upper case letter. Digits are treated as words.
 	for ; i < len(s); i++ {
-		c := fix(s[i])
+		c := s[i]
 		if c == '_' && i+1 < len(s) && isASCIILower(s[i+1]) {
 			continue // Skip the underscore in s.
 		}
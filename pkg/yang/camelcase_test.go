@@ -33,6 +33,10 @@ func TestCamelCase(t *testing.T) {
 		{"_a_", "XA_"},
 		{"ietf-interface", "IETFInterface"},
 		{"ietf-interface-1", "IETFInterface_1"},
+		{"café", "Caf_"},
+		{"über-fast", "XBerFast"},
+		{"a.b c", "ABC"},
+		{"日本", "X_"},
 	}
 	for _, tc := range tests {
 		if got := CamelCase(tc.in); got != tc.want {
@@ -40,3 +44,19 @@ func TestCamelCase(t *testing.T) {
 		}
 	}
 }
+
+func TestSanitizeIdentifier(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"one_Two3", "one_Two3"},
+		{"ietf-if:name/x", "ietf_if_name_x"},
+		{"café", "caf_"},
+		{"日本", "__"},
+	} {
+		if got := SanitizeIdentifier(tc.in); got != tc.want {
+			t.Errorf("SanitizeIdentifier(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -71,6 +71,7 @@ type Entry struct {
 	Name        string    // our name, same as the key in our parent Dirs
 	Description string    // description from node, if any
 	Default     string    // default from node, if any
+	Defaults    []string  // all defaults from a leaf-list node, if any
 	Errors      []error   // list of errors encounterd on this node
 	Kind        EntryKind // kind of Entry
 	Config      TriState  // config state of this entry, if known
@@ -178,6 +179,7 @@ const (
 	NotificationEntry
 	OutputEntry
 	TypedefEntry // taewony
+	AnyDataEntry
 )
 
 // EntryKindToName maps EntryKind to their names
@@ -191,6 +193,7 @@ var EntryKindToName = map[EntryKind]string{
 	NotificationEntry: "Notification",
 	OutputEntry:       "Output",
 	TypedefEntry:      "Typedef", // taewony
+	AnyDataEntry:      "AnyData",
 }
 
 func (k EntryKind) String() string {
@@ -326,15 +329,26 @@ func (e *Entry) add(key string, value *Entry) *Entry {
 }
 
 // entryCache is used to prevent unnecessary recursion into previously
-// converted nodes.
+// converted nodes.  It is only used for nodes that were not read into a
+// Modules, which have a cache of their own.
 var entryCache = map[Node]*Entry{}
 
 // mergedSubmodule is used to prevent re-parsing a submodule that has already
 // been merged into a particular entity when circular dependencies are being
 // ignored. The keys of the map are a string that is formed by concatenating
-// the name of the including (sub)module and the included submodule.
+// the name of the including (sub)module and the included submodule.  Like
+// entryCache it is only used for nodes that were not read into a Modules.
 var mergedSubmodule = map[string]bool{}
 
+// entryState returns the entry cache and merged submodules for n: those of
+// the Modules n was read into, or the package level ones if there is none.
+func entryState(n Node) (map[Node]*Entry, map[string]bool) {
+	if m := RootNode(n); m != nil && m.modules != nil {
+		return m.modules.entryCache, m.modules.mergedSubmodule
+	}
+	return entryCache, mergedSubmodule
+}
+
 var depth = 0
 
 // ToEntry expands node n into a directory Entry.  Expansion is based on the
@@ -351,6 +365,7 @@ func ToEntry(n Node) (e *Entry) {
 			Errors: []error{err},
 		}
 	}
+	entryCache, mergedSubmodule := entryState(n)
 	if e := entryCache[n]; e != nil {
 		return e
 	}
@@ -432,6 +447,13 @@ func ToEntry(n Node) (e *Entry) {
 		}
 
 		e := ToEntry(leaf)
+		// YANG 1.1 allows a leaf-list to have multiple defaults.
+		for _, d := range s.Default {
+			e.Defaults = append(e.Defaults, d.Name)
+		}
+		if len(e.Defaults) > 0 {
+			e.Default = e.Defaults[0]
+		}
 		e.ListAttr = &ListAttr{
 			MinElements: s.MinElements,
 			MaxElements: s.MaxElements,
@@ -471,6 +493,8 @@ func ToEntry(n Node) (e *Entry) {
 		e.Kind = CaseEntry
 	case *AnyXML:
 		e.Kind = AnyXMLEntry
+	case *AnyData:
+		e.Kind = AnyDataEntry
 	case *Input:
 		e.Kind = InputEntry
 	case *Output:
@@ -513,6 +537,10 @@ func ToEntry(n Node) (e *Entry) {
 				ne.Parent = e
 				e.Augments = append(e.Augments, ne)
 			}
+		case "anydata":
+			for _, a := range fv.Interface().([]*AnyData) {
+				e.add(a.Name, ToEntry(a))
+			}
 		case "anyxml":
 			for _, a := range fv.Interface().([]*AnyXML) {
 				e.add(a.Name, ToEntry(a))
@@ -600,7 +628,12 @@ func ToEntry(n Node) (e *Entry) {
 			// seems fine to ignore them for now, we are
 			// just interested in the tree structure.
 			for _, r := range fv.Interface().([]*RPC) {
-				e.add(r.Name, ToEntry(r))
+				re := ToEntry(r)
+				// An RPC need not have an input or output.
+				if re.RPC == nil {
+					re.RPC = &RPCEntry{}
+				}
+				e.add(r.Name, re)
 			}
 
 		case "input":
@@ -828,6 +861,57 @@ func (e *Entry) Path() string {
 	return e.Parent.Path() + "/" + e.Name
 }
 
+// FullPath returns the absolute schema path to e, such as /mod:top/child/leaf.
+// The first element is qualified with the name of the module and nodes added
+// by an augment in another module are qualified with the name of that module.
+// A nil Entry, or the Entry of a module, returns "".
+func (e *Entry) FullPath() string {
+	if e == nil || e.Parent == nil {
+		return ""
+	}
+	return e.Parent.FullPath() + "/" + e.PathName()
+}
+
+// PathName returns the name of e as an element of FullPath: the name of a
+// top-level node is qualified with the name of its module and the name of a
+// node added by an augment in another module with the name of that module.
+func (e *Entry) PathName() string {
+	switch {
+	case e.Parent == nil:
+		return e.Name
+	case e.Parent.Parent == nil:
+		return e.Parent.Name + ":" + e.Name
+	}
+	if m := e.augmentedBy(); m != "" {
+		return m + ":" + e.Name
+	}
+	return e.Name
+}
+
+// augmentedBy returns the name of the module that augmented e into the tree,
+// or "" if e was not added by an augment from a different module.
+func (e *Entry) augmentedBy() string {
+	if e.Node == nil {
+		return ""
+	}
+	a, ok := e.Node.ParentNode().(*Augment)
+	if !ok {
+		return ""
+	}
+	name := ModuleName(a)
+	if name == "" {
+		return ""
+	}
+	root := e
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	if name == root.Name {
+		return ""
+	}
+	return name
+}
+
 // Namespace returns the YANG/XML namespace Value for e as mounted in the Entry
 // tree (e.g., as placed by grouping statements).
 //
@@ -852,6 +936,23 @@ func (e *Entry) Namespace() *Value {
 	return new(Value)
 }
 
+// ModuleNamespace returns the namespace URI of the module e is in, or "" if
+// it is not known.  Entries of a submodule are in the namespace of the
+// module the submodule belongs to.
+func ModuleNamespace(e *Entry) string {
+	if ns := e.Namespace(); ns != nil {
+		return ns.Name
+	}
+	for ; e.Parent != nil; e = e.Parent {
+	}
+	if root := RootNode(e.Node); root != nil && root.BelongsTo != nil && root.modules != nil {
+		if m := root.modules.Modules[root.BelongsTo.Name]; m != nil && m.Namespace != nil {
+			return m.Namespace.Name
+		}
+	}
+	return ""
+}
+
 // dup makes a deep duplicate of e.
 func (e *Entry) dup() *Entry {
 	// Warning: if we add any elements to Entry that should not be
@@ -268,6 +268,38 @@ func TestEntryNamespace(t *testing.T) {
 	}
 }
 
+func TestModuleNamespace(t *testing.T) {
+	ms := NewModules()
+	for name, src := range map[string]string{
+		"top": `module top { namespace "urn:top"; prefix "t"; include sub; container c { leaf l { type string; } } }`,
+		"sub": `submodule sub { belongs-to top { prefix "t"; } container s { } }`,
+	} {
+		if err := ms.Parse(src, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	top, err := ms.GetModule("top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		e    *Entry
+		want string
+	}{
+		{top, "urn:top"},
+		{top.Dir["c"].Dir["l"], "urn:top"},
+		{ToEntry(ms.SubModules["sub"]), "urn:top"},
+		{&Entry{Name: "detached"}, ""},
+	} {
+		if got := ModuleNamespace(tt.e); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.e.Name, got, tt.want)
+		}
+	}
+}
+
 func TestIgnoreCircularDependencies(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -668,7 +700,6 @@ func TestFullModuleProcess(t *testing.T) {
 
 	for _, tt := range tests {
 		ms := NewModules()
-		mergedSubmodule = map[string]bool{}
 
 		ParseOptions.IgnoreSubmoduleCircularDependencies = tt.inIgnoreCircDeps
 		for n, m := range tt.inModules {
@@ -710,3 +741,57 @@ func TestFullModuleProcess(t *testing.T) {
 		}
 	}
 }
+
+func TestFullPath(t *testing.T) {
+	ms := NewModules()
+	for name, src := range map[string]string{
+		"base.yang": `
+module base {
+  namespace "urn:base";
+  prefix "b";
+
+  leaf hostname { type string; }
+  container system {
+    container clock { leaf timezone { type string; } }
+  }
+}
+`,
+		"extra.yang": `
+module extra {
+  namespace "urn:extra";
+  prefix "x";
+  import base { prefix b; }
+
+  augment "/b:system/b:clock" {
+    leaf utc-offset { type int16; }
+  }
+}
+`,
+	} {
+		if err := ms.Parse(src, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	base, errs := ms.GetModule("base")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	clock := base.Dir["system"].Dir["clock"]
+	for _, tt := range []struct {
+		e    *Entry
+		want string
+	}{
+		{nil, ""},
+		{base, ""},
+		{base.Dir["hostname"], "/base:hostname"},
+		{clock.Dir["timezone"], "/base:system/clock/timezone"},
+		{clock.Dir["utc-offset"], "/base:system/clock/extra:utc-offset"},
+	} {
+		if got := tt.e.FullPath(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,8 @@
 package yang
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -70,16 +72,38 @@ func PathsWithModules(root string) (paths []string, err error) {
 }
 
 // readFile makes testing of findFile easier.
-var readFile = ioutil.ReadFile
+var readFile = readYangFile
+
+// gzipMagic are the first bytes of gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readYangFile returns the contents of the file name.  Gzip compressed files,
+// such as the .yang.gz files found in module archives, are decompressed.
+func readYangFile(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil || !bytes.HasPrefix(data, gzipMagic) {
+		return data, err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	defer r.Close()
+	if data, err = ioutil.ReadAll(r); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return data, nil
+}
 
 // scanDir makes testing of findFile easier.
 var scanDir = findInDir
 
 // findFile returns the name and contents of the .yang file associated with
 // name, or an error.  If name is a module name rather than a file name (it does
-// not have a .yang extension and there is no / in name), .yang is appended to
-// the the name.  The directory that the .yang file is found in is added to Path
-// if not already in Path. If a file is not found by exact match, directories
+// not have a .yang or .yang.gz extension and there is no / in name), .yang is
+// appended to the the name.  Gzip compressed files are decompressed.  The
+// directory that the .yang file is found in is added to Path if not already
+// in Path. If a file is not found by exact match, directories
 // are scanned for "name@revision-date.yang" files, the latest (sorted by
 // YYYY-MM-DD revision-date) of these will be selected.
 //
@@ -90,7 +114,7 @@ var scanDir = findInDir
 // Path.
 func findFile(name string) (string, string, error) {
 	slash := strings.Index(name, "/")
-	if slash < 0 && !strings.HasSuffix(name, ".yang") {
+	if slash < 0 && !strings.HasSuffix(name, ".yang") && !strings.HasSuffix(name, ".yang.gz") {
 		name += ".yang"
 		if best := scanDir(".", name, false); best != "" {
 			// we found a matching candidate in the local directory
@@ -15,6 +15,8 @@
 package yang
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"io/ioutil"
 	"os"
@@ -81,7 +83,7 @@ func TestScanForPathsAndAddModules(t *testing.T) {
 	defer testPathReset()
 
 	// disable any readFile mock setup by other tests
-	readFile = ioutil.ReadFile
+	readFile = readYangFile
 
 	// Scan the directory tree for YANG modules
 	paths, err := PathsWithModules("../../testdata")
@@ -112,3 +114,64 @@ func TestScanForPathsAndAddModules(t *testing.T) {
 	}
 
 }
+
+func TestReadGzip(t *testing.T) {
+	defer testPathReset()
+	defer func(r func(string) ([]byte, error)) { readFile = r }(readFile)
+	readFile = readYangFile
+
+	const src = `
+module zipped {
+  namespace "urn:zipped";
+  prefix "z";
+  leaf name { type string; }
+}
+`
+	dir, err := ioutil.TempDir("", "yang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(src))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "zipped.yang")
+	zipped := filepath.Join(dir, "zipped.yang.gz")
+	if err := ioutil.WriteFile(plain, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(zipped, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, want, err := findFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, got, err := findFile(zipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	ms := NewModules()
+	if err := ms.Read(zipped); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	e, errs := ms.GetModule("zipped")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if e.Dir["name"] == nil {
+		t.Errorf("leaf name missing from %s", zipped)
+	}
+}
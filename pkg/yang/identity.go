@@ -29,9 +29,18 @@ type identityDictionary struct {
 	dict map[string]resolvedIdentity
 }
 
-// Global dictionary of resolved identities
+// Global dictionary of resolved identities of modules not read into a
+// Modules, which have a dictionary of their own.
 var identities = identityDictionary{dict: map[string]resolvedIdentity{}}
 
+// identitiesFor returns the dictionary of resolved identities for mod.
+func identitiesFor(mod *Module) *identityDictionary {
+	if mod != nil && mod.modules != nil {
+		return mod.modules.identities
+	}
+	return &identities
+}
+
 // resolvedIdentity is an Identity that has been disambiguated.
 type resolvedIdentity struct {
 	Module   *Module
@@ -81,6 +90,7 @@ func (mod *Module) findIdentityBase(baseStr string) (*resolvedIdentity, []error)
 	var ok bool
 	var errs []error
 
+	identities := identitiesFor(mod)
 	basePrefix, baseName := getPrefix(baseStr)
 	rootPrefix := mod.GetPrefix()
 
@@ -109,7 +119,7 @@ func (mod *Module) findIdentityBase(baseStr string) (*resolvedIdentity, []error)
 		for _, rid := range extmod.Identities() {
 			if rid.Name == baseName {
 				key := rid.PrefixedName()
-				if id, ok := identities.dict[key]; ok {
+				if id, ok := identitiesFor(extmod).dict[key]; ok {
 					base = id
 				} else {
 					errs = append(errs, fmt.Errorf("can't find base %s", baseStr))
@@ -127,6 +137,7 @@ func (mod *Module) findIdentityBase(baseStr string) (*resolvedIdentity, []error)
 }
 
 func (ms *Modules) resolveIdentities() []error {
+	identities := ms.identities
 	defer identities.mu.Unlock()
 	identities.mu.Lock()
 
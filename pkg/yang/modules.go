@@ -28,6 +28,37 @@ type Modules struct {
 	includes   map[*Module]bool   // Modules we have already done include on
 	byPrefix   map[string]*Module // Cache of prefix lookup
 	byNS       map[string]*Module // Cache of namespace lookup
+
+	// The state below belongs to ms rather than being global so
+	// independent Modules can be processed concurrently.
+	typeDict        *TypeDictionary     // Typedefs of the modules in ms
+	identities      *identityDictionary // Resolved identities of ms
+	entryCache      map[Node]*Entry     // See ToEntry
+	mergedSubmodule map[string]bool     // See ToEntry
+
+	// BestEffort makes Process build the entries of the modules even
+	// when some of their imports or types cannot be found.  Those are
+	// reported as UnresolvedErrors along with the other errors.
+	BestEffort bool
+	unresolved []error // imports skipped by include, see BestEffort
+}
+
+// An UnresolvedError reports an import or type that could not be found.
+// With BestEffort set the rest of the modules are still processed.
+type UnresolvedError struct {
+	Err error
+}
+
+func (e *UnresolvedError) Error() string { return e.Err.Error() }
+
+// allUnresolved returns true if every error in errs is an UnresolvedError.
+func allUnresolved(errs []error) bool {
+	for _, err := range errs {
+		if _, ok := err.(*UnresolvedError); !ok {
+			return false
+		}
+	}
+	return true
 }
 
 // NewModules returns a newly created and initialized Modules.
@@ -38,9 +69,20 @@ func NewModules() *Modules {
 		includes:   map[*Module]bool{},
 		byPrefix:   map[string]*Module{},
 		byNS:       map[string]*Module{},
+
+		typeDict:        &TypeDictionary{dict: map[Node]map[string]*Typedef{}},
+		identities:      &identityDictionary{dict: map[string]resolvedIdentity{}},
+		entryCache:      map[Node]*Entry{},
+		mergedSubmodule: map[string]bool{},
 	}
 }
 
+// TypeDict returns the dictionary of the typedefs defined by the modules and
+// submodules read into ms.
+func (ms *Modules) TypeDict() *TypeDictionary {
+	return ms.typeDict
+}
+
 // Read reads the named yang module into ms.  The name can be the name of an
 // actual .yang file or a module/submodule name (the base name of a .yang file,
 // e.g., foo.yang is named foo).  An error is returned if the file is not
@@ -136,6 +178,7 @@ func (ms *Modules) add(n Node) error {
 	mod := n.(*Module)
 	fullName := mod.FullName()
 	mod.modules = ms
+	ms.typeDict.claim(&TypeDict, mod)
 
 	if o := m[fullName]; o != nil {
 		return fmt.Errorf("duplicate %s %s at %s and %s", kind, fullName, Source(o), Source(n))
@@ -274,13 +317,14 @@ func (ms *Modules) process() []error {
 			errs = append(errs, err)
 		}
 	}
+	errs = append(errs, ms.unresolved...)
 
 	// Resolve identities before resolving typedefs, otherwise when we resolve a
 	// typedef that has an identityref within it, then the identity dictionary
 	// has not yet been built.
 	errs = append(errs, ms.resolveIdentities()...)
 	// Append any errors found trying to resolve typedefs
-	errs = append(errs, resolveTypedefs()...)
+	errs = append(errs, ms.resolveTypedefs()...)
 
 	return errs
 }
@@ -313,15 +357,19 @@ func (ms *Modules) process() []error {
 // Process may return multiple errors if multiple errors were encountered
 // while processing.  Even though multiple errors may be returned, this does
 // not mean these are all the errors.  Process will terminate processing early
-// based on the type and location of the error.
+// based on the type and location of the error.  With BestEffort set it does
+// not terminate for UnresolvedErrors, returning them once the entries are
+// built.
 func (ms *Modules) Process() []error {
-	// Reset globals that may remain stale if multiple Process() calls are
+	// Reset state that may remain stale if multiple Process() calls are
 	// made by the same caller.
-	mergedSubmodule = map[string]bool{}
-	entryCache = map[Node]*Entry{}
+	ms.mergedSubmodule = map[string]bool{}
+	ms.entryCache = map[Node]*Entry{}
+
+	ms.unresolved = nil
 
 	errs := ms.process()
-	if len(errs) > 0 {
+	if len(errs) > 0 && !(ms.BestEffort && allUnresolved(errs)) {
 		return errorSort(errs)
 	}
 
@@ -332,7 +380,7 @@ func (ms *Modules) Process() []error {
 		errs = append(errs, ToEntry(m).GetErrors()...)
 	}
 
-	if len(errs) > 0 {
+	if len(errs) > 0 && !(ms.BestEffort && allUnresolved(errs)) {
 		return errorSort(errs)
 	}
 
@@ -409,7 +457,12 @@ func (ms *Modules) include(m *Module) error {
 	for _, i := range m.Import {
 		im := ms.FindModule(i)
 		if im == nil {
-			return fmt.Errorf("no such module: %s", i.Name)
+			err := &UnresolvedError{fmt.Errorf("no such module: %s", i.Name)}
+			if !ms.BestEffort {
+				return err
+			}
+			ms.unresolved = append(ms.unresolved, err)
+			continue
 		}
 		// Process the include statements in our included module.
 		if err := ms.include(im); err != nil {
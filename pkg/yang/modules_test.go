@@ -16,6 +16,7 @@ package yang_test
 
 import (
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/paranpen/yangc/pkg/yang"
@@ -131,3 +132,46 @@ func TestModulesFindByNamespace(t *testing.T) {
 		testModulesFindByCommonHandler(t, i, got, tc.want, tc.wantError, err)
 	}
 }
+
+// TestConcurrentProcess compiles the same module in independent Modules at
+// the same time.  Run with -race to check they share no unguarded state.
+func TestConcurrentProcess(t *testing.T) {
+	const src = `
+module concurrent {
+  prefix "c";
+  namespace "urn:concurrent";
+
+  typedef port { type uint16; }
+  identity base;
+  identity derived { base base; }
+  container server {
+    leaf port { type port; }
+    leaf kind { type identityref { base base; } }
+  }
+}
+`
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ms := yang.NewModules()
+			if err := ms.Parse(src, "concurrent.yang"); err != nil {
+				t.Error(err)
+				return
+			}
+			e, errs := ms.GetModule("concurrent")
+			if len(errs) > 0 {
+				t.Error(errs)
+				return
+			}
+			if p := e.Dir["server"].Dir["port"]; p == nil || p.Type.Kind != yang.Yuint16 {
+				t.Errorf("leaf port not resolved to uint16: %v", p)
+			}
+			if tds := ms.TypeDict().Typedefs(); len(tds) != 1 || tds[0].Name != "port" {
+				t.Errorf("got typedefs %v, want only port", tds)
+			}
+		}()
+	}
+	wg.Wait()
+}
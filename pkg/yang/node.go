@@ -134,6 +134,20 @@ func RootNode(n Node) *Module {
 	return nil
 }
 
+// ModuleName returns the name of the module that n was defined in.  For a
+// node of a submodule this is the module the submodule belongs to.  "" is
+// returned if n is not part of a module.
+func ModuleName(n Node) string {
+	m := RootNode(n)
+	switch {
+	case m == nil:
+		return ""
+	case m.BelongsTo != nil:
+		return m.BelongsTo.Name
+	}
+	return m.Name
+}
+
 // FindNode finds the node referenced by path relative to n.  If path does not
 // reference a node then nil is returned (i.e. path not found).  The path looks
 // similar to an XPath but curently has no wildcarding.  For example:
@@ -157,6 +157,27 @@ func (n Number) Int() (int64, error) {
 	return 0, errors.New("signed integer overflow")
 }
 
+// Float returns n as a float64.  Decimal numbers return their decimal
+// value, integers are converted.  It returns an error if n is min or max.
+func (n Number) Float() (float64, error) {
+	var f float64
+	switch n.Kind {
+	case MinNumber:
+		return 0, errors.New("min has no float value")
+	case MaxNumber:
+		return 0, errors.New("max has no float value")
+	}
+	if n.Decimal != 0 {
+		f = n.Decimal
+	} else {
+		f = float64(n.Value)
+	}
+	if n.Kind == Negative {
+		f = -f
+	}
+	return f, nil
+}
+
 // add adds i to n without checking overflow.  We really only need to be
 // able to add 1 for our code.
 func (n Number) add(i uint64) Number {
@@ -346,6 +367,26 @@ func (r YangRange) Validate() error {
 	return nil
 }
 
+// bounded returns r with the keywords min and max replaced by the lowest and
+// highest values of base, which is the range r restricts.  r is returned
+// unchanged if base is empty.
+func (r YangRange) bounded(base YangRange) YangRange {
+	if len(r) == 0 || len(base) == 0 {
+		return r
+	}
+	br := make(YangRange, len(r))
+	copy(br, r)
+	for i := range br {
+		if br[i].Min.Kind == MinNumber {
+			br[i].Min = base[0].Min
+		}
+		if br[i].Max.Kind == MaxNumber {
+			br[i].Max = base[len(base)-1].Max
+		}
+	}
+	return br
+}
+
 // Equal returns true if ranges r and q are identically equivalent.
 // TODO(borman): should we coalesce ranges in the comparison?
 func (r YangRange) Equal(q YangRange) bool {
@@ -399,3 +440,53 @@ func (r YangRange) Contains(s YangRange) bool {
 	}
 	return true
 }
+
+// Intersect returns the values possible in both r and s as a sorted and
+// coalesced range.  r and s are assumed to be sorted and coalesced.  Unlike
+// Contains, an empty range has no values, so disjoint ranges intersect to an
+// empty range.
+func (r YangRange) Intersect(s YangRange) YangRange {
+	var ir YangRange
+	i, j := 0, 0
+	for i < len(r) && j < len(s) {
+		lo, hi := r[i].Min, r[i].Max
+		if lo.Less(s[j].Min) {
+			lo = s[j].Min
+		}
+		if s[j].Max.Less(hi) {
+			hi = s[j].Max
+		}
+		if !hi.Less(lo) {
+			ir = append(ir, YRange{lo, hi})
+		}
+		// Advance whichever range ends first.
+		if r[i].Max.Less(s[j].Max) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return coalesce(ir)
+}
+
+// Union returns the values possible in either r or s as a sorted and
+// coalesced range.  r and s are assumed to be valid.
+func (r YangRange) Union(s YangRange) YangRange {
+	ur := make(YangRange, 0, len(r)+len(s))
+	ur = append(ur, r...)
+	ur = append(ur, s...)
+	sort.Sort(ur)
+	return coalesce(ur)
+}
+
+// NormalizeRange returns the YANG range s in canonical form: sorted, with
+// adjacent and overlapping ranges coalesced.  Two ranges allow the same
+// values if their canonical forms are equal.  An error is returned if s is
+// not a valid range.
+func NormalizeRange(s string) (string, error) {
+	r, err := ParseRanges(s)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
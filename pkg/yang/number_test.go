@@ -65,3 +65,34 @@ func TestNumberAdd(t *testing.T) {
 		}
 	}
 }
+
+func TestNumberFloat(t *testing.T) {
+	for x, tt := range []struct {
+		in  string
+		out float64
+		err bool
+	}{
+		{in: "42", out: 42},
+		{in: "0", out: 0},
+		{in: "-17", out: -17},
+		{in: "3.25", out: 3.25},
+		{in: "-0.5", out: -0.5},
+		{in: "-12.75", out: -12.75},
+		{in: "min", err: true},
+		{in: "max", err: true},
+	} {
+		n, err := ParseNumber(tt.in)
+		if err != nil {
+			t.Fatalf("#%d: ParseNumber(%q): %v", x, tt.in, err)
+		}
+		f, err := n.Float()
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("#%d: %s: got %v, want error", x, tt.in, f)
+		case !tt.err && err != nil:
+			t.Errorf("#%d: %s: unexpected error %v", x, tt.in, err)
+		case f != tt.out:
+			t.Errorf("#%d: %s: got %v, want %v", x, tt.in, f, tt.out)
+		}
+	}
+}
@@ -22,6 +22,11 @@ type Options struct {
 	// package will explicitly ignore the case where a submodule will include
 	// itself through a circular reference.
 	IgnoreSubmoduleCircularDependencies bool
+
+	// AllowEnumAliases specifies whether members of an enumeration may share
+	// a value, which RFC 7950 forbids.  Setting this value to true makes the
+	// members that reuse a value aliases of the member first assigned it.
+	AllowEnumAliases bool
 }
 
 // ParseOptions sets the options for the current YANG module parsing. It can be
@@ -86,6 +86,10 @@ func (s *Statement) Keyword() string { return s.keyword }
 // SubStatements returns a slice of Statements found in s.
 func (s *Statement) SubStatements() []*Statement { return s.statements }
 
+// Position returns the file, and the 1's based line and column, where s was
+// defined.  Unknown parts are returned as "" or 0.
+func (s *Statement) Position() (file string, line, col int) { return s.file, s.line, s.col }
+
 // String returns s's tree as a string.
 func (s *Statement) String() string {
 	var b bytes.Buffer
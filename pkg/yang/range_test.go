@@ -180,6 +180,64 @@ func TestCoalesce(t *testing.T) {
 	}
 }
 
+func TestRangeIntersect(t *testing.T) {
+	for x, tt := range []struct {
+		r1, r2, out YangRange
+	}{
+		{},
+		{r1: YangRange{R(1, 4)}},
+		{YangRange{R(1, 4)}, YangRange{R(1, 4)}, YangRange{R(1, 4)}},
+		// overlapping
+		{YangRange{R(1, 5)}, YangRange{R(3, 8)}, YangRange{R(3, 5)}},
+		{YangRange{R(1, 10)}, YangRange{R(2, 3), R(5, 6)}, YangRange{R(2, 3), R(5, 6)}},
+		{YangRange{R(1, 3), R(6, 9)}, YangRange{R(2, 7)}, YangRange{R(2, 3), R(6, 7)}},
+		{YangRange{R(useMin, 0)}, YangRange{R(-5, useMax)}, YangRange{R(-5, 0)}},
+		{YangRange{R(useMin, useMax)}, YangRange{R(-5, 5)}, YangRange{R(-5, 5)}},
+		// adjacent
+		{YangRange{R(1, 3)}, YangRange{R(3, 5)}, YangRange{R(3, 3)}},
+		{YangRange{R(1, 2)}, YangRange{R(3, 4)}, nil},
+		// disjoint
+		{YangRange{R(1, 2)}, YangRange{R(5, 6)}, nil},
+		{YangRange{R(1, 2), R(8, 9)}, YangRange{R(4, 6)}, nil},
+	} {
+		out := tt.r1.Intersect(tt.r2)
+		if !out.Equal(tt.out) {
+			t.Errorf("#%d: %v intersect %v: got %v, want %v", x, tt.r1, tt.r2, out, tt.out)
+		}
+		if out := tt.r2.Intersect(tt.r1); !out.Equal(tt.out) {
+			t.Errorf("#%d: %v intersect %v: got %v, want %v", x, tt.r2, tt.r1, out, tt.out)
+		}
+	}
+}
+
+func TestRangeUnion(t *testing.T) {
+	for x, tt := range []struct {
+		r1, r2, out YangRange
+	}{
+		{},
+		{YangRange{R(1, 4)}, nil, YangRange{R(1, 4)}},
+		{YangRange{R(1, 4)}, YangRange{R(1, 4)}, YangRange{R(1, 4)}},
+		// overlapping
+		{YangRange{R(1, 5)}, YangRange{R(3, 8)}, YangRange{R(1, 8)}},
+		{YangRange{R(1, 3), R(6, 9)}, YangRange{R(2, 7)}, YangRange{R(1, 9)}},
+		{YangRange{R(useMin, 0)}, YangRange{R(-5, useMax)}, YangRange{R(useMin, useMax)}},
+		// adjacent
+		{YangRange{R(1, 2)}, YangRange{R(3, 4)}, YangRange{R(1, 4)}},
+		{YangRange{R(-2, -1)}, YangRange{R(0, 4)}, YangRange{R(-2, 4)}},
+		// disjoint
+		{YangRange{R(1, 2)}, YangRange{R(5, 6)}, YangRange{R(1, 2), R(5, 6)}},
+		{YangRange{R(1, 2), R(8, 9)}, YangRange{R(4, 6)}, YangRange{R(1, 2), R(4, 6), R(8, 9)}},
+	} {
+		out := tt.r1.Union(tt.r2)
+		if !out.Equal(tt.out) {
+			t.Errorf("#%d: %v union %v: got %v, want %v", x, tt.r1, tt.r2, out, tt.out)
+		}
+		if out := tt.r2.Union(tt.r1); !out.Equal(tt.out) {
+			t.Errorf("#%d: %v union %v: got %v, want %v", x, tt.r2, tt.r1, out, tt.out)
+		}
+	}
+}
+
 func TestYANGRangeDecimal64(t *testing.T) {
 	mod := `
 module test {
@@ -203,3 +261,29 @@ module test {
 		t.Errorf("error %d: %v", i, err)
 	}
 }
+
+func TestNormalizeRange(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+		err     bool
+	}{
+		{in: "1..10", out: "1..10"},
+		{in: "5..10|1..3|4", out: "1..10"},
+		{in: "1..3|4|5..10", out: "1..10"},
+		{in: "7|1..2", out: "1..2|7"},
+		{in: "3..3", out: "3"},
+		{in: "10..1", err: true},
+		{in: "1..2..3", err: true},
+		{in: "x", err: true},
+	} {
+		out, err := NormalizeRange(tt.in)
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("%q: got %q, want error", tt.in, out)
+		case !tt.err && err != nil:
+			t.Errorf("%q: %v", tt.in, err)
+		case out != tt.out:
+			t.Errorf("%q: got %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
@@ -31,15 +31,45 @@ type TypeDictionary struct {
 	dict map[Node]map[string]*Typedef
 }
 
-// TypeDict is a protected global dictionary of all typedefs.
-// TODO(borman): should this be made as part of some other structure, rather
-// than a singleton.  That can be done later when we replumb everything to more
-// or less pass around an extra pointer.  That is not needed until such time
-// that we plan for a single application to process completely independent YANG
-// modules where there may be conflicts between the modules or we plan to
-// process them completely independently of eachother.
+// TypeDict is a protected global dictionary of the typedefs of the modules
+// built by BuildAST.  When a module is added to a Modules its typedefs move
+// to the dictionary of that Modules, see Modules.TypeDict, so independent
+// Modules do not share typedefs and can be processed concurrently.
 var TypeDict = TypeDictionary{dict: map[Node]map[string]*Typedef{}}
 
+// typeDictFor returns the dictionary holding the typedefs visible from n:
+// that of the Modules n was read into, or TypeDict if there is none.
+func typeDictFor(n Node) *TypeDictionary {
+	if m := RootNode(n); m != nil && m.modules != nil {
+		return m.modules.typeDict
+	}
+	return &TypeDict
+}
+
+// claim moves the typedefs of nodes rooted at m from the dictionary from
+// to d.
+func (d *TypeDictionary) claim(from *TypeDictionary, m *Module) {
+	from.mu.Lock()
+	var claimed []Node
+	for n := range from.dict {
+		if RootNode(n) == m {
+			claimed = append(claimed, n)
+		}
+	}
+	moved := map[Node]map[string]*Typedef{}
+	for _, n := range claimed {
+		moved[n] = from.dict[n]
+		delete(from.dict, n)
+	}
+	from.mu.Unlock()
+
+	defer d.mu.Unlock()
+	d.mu.Lock()
+	for n, tds := range moved {
+		d.dict[n] = tds
+	}
+}
+
 // add adds an entry to the TypeDictionary d.
 func (d *TypeDictionary) add(n Node, name string, td *Typedef) {
 	defer d.mu.Unlock()
@@ -65,15 +95,15 @@ func (d *TypeDictionary) find(n Node, name string) *Typedef {
 func (d *TypeDictionary) findExternal(n Node, prefix, name string) (*Typedef, error) {
 	root := FindModuleByPrefix(n, prefix)
 	if root == nil {
-		return nil, fmt.Errorf("%s: unknown prefix: %s for type %s", Source(n), prefix, name)
+		return nil, &UnresolvedError{fmt.Errorf("%s: unknown prefix: %s for type %s", Source(n), prefix, name)}
 	}
-	if td := d.find(root, name); td != nil {
+	if td := typeDictFor(root).find(root, name); td != nil {
 		return td, nil
 	}
 	if prefix != "" {
 		name = prefix + ":" + name
 	}
-	return nil, fmt.Errorf("%s: unknown type %s", Source(n), name)
+	return nil, &UnresolvedError{fmt.Errorf("%s: unknown type %s", Source(n), name)}
 }
 
 // Typedefs returns a slice of all typedefs in d.
@@ -100,14 +130,14 @@ func addTypedefs(t Typedefer) {
 
 // resolveTypedefs is called after all of modules and submodules have been read,
 // as well as their imports and includes.  It resolves all typedefs found in all
-// modules and submodules read in.
-func resolveTypedefs() []error {
+// modules and submodules read into ms.
+func (ms *Modules) resolveTypedefs() []error {
 	var errs []error
 
 	// When resolve typedefs, we may need to look up other typedefs.
 	// We gather all typedefs into a slice so we don't deadlock on
-	// TypeDict.
-	for _, td := range TypeDict.Typedefs() {
+	// the dictionary.
+	for _, td := range ms.typeDict.Typedefs() {
 		errs = append(errs, td.resolve()...)
 	}
 	return errs
@@ -182,13 +212,13 @@ check:
 		// If we have no prefix, or the prefix is what we call our own
 		// root, then we look in our ancestors for a typedef of name.
 		for n := Node(t); n != nil; n = n.ParentNode() {
-			if td = TypeDict.find(n, name); td != nil {
+			if td = typeDictFor(n).find(n, name); td != nil {
 				break check
 			}
 		}
 		// We need to check our sub-modules as well
 		for _, in := range root.Include {
-			if td = TypeDict.find(in.Module, name); td != nil {
+			if td = typeDictFor(in.Module).find(in.Module, name); td != nil {
 				break check
 			}
 		}
@@ -200,7 +230,7 @@ check:
 			pname = fmt.Sprintf("%s[%s]:%s", prefix, root.Prefix.Name, t.Name)
 		}
 
-		return []error{fmt.Errorf("%s: unknown type: %s", Source(t), pname)}
+		return []error{&UnresolvedError{fmt.Errorf("%s: unknown type: %s", Source(t), pname)}}
 
 	default:
 		source = "imported"
@@ -208,7 +238,7 @@ check:
 		// what module it is part of and if it is defined at the top
 		// level of that module.
 		var err error
-		td, err = TypeDict.findExternal(t, prefix, name)
+		td, err = typeDictFor(t).findExternal(t, prefix, name)
 		if err != nil {
 			return []error{err}
 		}
@@ -242,8 +272,13 @@ check:
 	switch {
 	case y.Kind == Ydecimal64 && (t.Name == "decimal64" || t.FractionDigits != nil):
 		i, err := t.FractionDigits.asRangeInt(1, 18)
-		if err != nil {
+		switch {
+		case err != nil:
 			errs = append(errs, fmt.Errorf("%s: %v", Source(t), err))
+		case t.Name != "decimal64" && int(i) != y.FractionDigits:
+			// A derived type may only restrict the range of its
+			// base, it cannot change the precision.
+			errs = append(errs, fmt.Errorf("%s: fraction-digits %d does not match %d of base type %s", Source(t), i, y.FractionDigits, t.Name))
 		}
 		y.FractionDigits = int(i)
 	case t.FractionDigits != nil:
@@ -276,19 +311,29 @@ check:
 
 	if t.Range != nil {
 		yr, err := ParseRanges(t.Range.Name)
+		if err == nil {
+			yr = yr.bounded(y.Range)
+		}
 		switch {
 		case err != nil:
 			errs = append(errs, fmt.Errorf("%s: bad range: %v", Source(t.Range), err))
 		case !y.Range.Contains(yr):
 			errs = append(errs, fmt.Errorf("%s: bad range: %v not within %v", Source(t.Range), yr, y.Range))
 		case yr.Equal(y.Range):
-		default:
+		case len(y.Range) == 0:
 			y.Range = yr
+		default:
+			// The effective range is what both we and the
+			// type we are derived from allow.
+			y.Range = y.Range.Intersect(yr)
 		}
 	}
 
 	if t.Length != nil {
 		yr, err := ParseRanges(t.Length.Name)
+		if err == nil {
+			yr = yr.bounded(y.Length)
+		}
 		switch {
 		case err != nil:
 			errs = append(errs, fmt.Errorf("%s: bad length: %v", Source(t.Length), err))
@@ -302,7 +347,13 @@ check:
 					break
 				}
 			}
-			y.Length = yr
+			if len(y.Length) == 0 {
+				y.Length = yr
+			} else {
+				// As with range, the effective length is what
+				// both we and our base allow.
+				y.Length = y.Length.Intersect(yr)
+			}
 		}
 	}
 
@@ -323,6 +374,7 @@ check:
 
 	if len(t.Enum) > 0 {
 		enum := NewEnumType()
+		enum.unique = !ParseOptions.AllowEnumAliases
 		for _, e := range t.Enum {
 			if err := set(enum, e.Name, e.Value); err != nil {
 				errs = append(errs, fmt.Errorf("%s: %v", Source(e), err))
@@ -387,3 +439,19 @@ looking:
 
 	return errs
 }
+
+// ResolveTypePrefix returns the name of the module that defines the typedef
+// t refers to, along with the name of the typedef.  A type that does not
+// refer to a typedef, such as a builtin type, returns an empty module and the
+// name of the type.
+func ResolveTypePrefix(t *YangType) (module, name string) {
+	if t == nil {
+		return "", ""
+	}
+	if t.Base != nil {
+		if td, ok := t.Base.Parent.(*Typedef); ok && td.Parent != nil {
+			return ModuleName(td), td.Name
+		}
+	}
+	return "", t.Name
+}
@@ -114,6 +114,7 @@ type EnumType struct {
 	unique   bool  // numeric values must be unique (enums)
 	toString map[int64]string
 	toInt    map[string]int64
+	order    []string // names in the order they were set
 }
 
 // NewEnumType returns an initialized EnumType.
@@ -161,6 +162,7 @@ func (e *EnumType) Set(name string, value int64) error {
 	}
 	e.toString[value] = name
 	e.toInt[name] = value
+	e.order = append(e.order, name)
 	if value >= e.last {
 		e.last = value
 	}
@@ -203,6 +205,12 @@ func (e *EnumType) Names() []string {
 	return names
 }
 
+// DeclaredNames returns the enum string names in the order they were set,
+// which for a parsed enumeration is the order they were declared in.
+func (e *EnumType) DeclaredNames() []string {
+	return append([]string(nil), e.order...)
+}
+
 type int64Slice []int64
 
 func (p int64Slice) Len() int           { return len(p) }
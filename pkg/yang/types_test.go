@@ -16,6 +16,7 @@ package yang
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -100,3 +101,183 @@ func TestTypeResolve(t *testing.T) {
 		}
 	}
 }
+
+func TestTypeRestrictions(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		typ  string
+		err  string
+	}{
+		{
+			name: "narrowed range",
+			typ:  `type uint8 { range "1..100"; }`,
+		},
+		{
+			name: "range out of base",
+			typ:  `type uint8 { range "1..300"; }`,
+			err:  "bad range: 1..300 not within 0..255",
+		},
+		{
+			name: "range min and max",
+			typ:  `type uint8 { range "min..10|20..max"; }`,
+		},
+		{
+			name: "range out of typedef",
+			typ:  `type small { range "1..200"; }`,
+			err:  "bad range: 1..200 not within 1..100",
+		},
+		{
+			name: "length out of typedef",
+			typ:  `type name { length "1..64"; }`,
+			err:  "bad length: 1..64 not within 1..32",
+		},
+		{
+			name: "same fraction-digits",
+			typ:  `type money { fraction-digits 2; range "0..100"; }`,
+		},
+		{
+			name: "changed fraction-digits",
+			typ:  `type money { fraction-digits 4; }`,
+			err:  "fraction-digits 4 does not match 2 of base type money",
+		},
+	} {
+		mod := `
+module test {
+  prefix test;
+  namespace urn:test;
+
+  typedef small { type uint8 { range "1..100"; } }
+  typedef name { type string { length "1..32"; } }
+  typedef money { type decimal64 { fraction-digits 2; } }
+
+  leaf l { ` + tt.typ + ` }
+}
+`
+		ms := NewModules()
+		if err := ms.Parse(mod, "test.yang"); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		errs := ms.Process()
+		switch {
+		case tt.err == "" && len(errs) > 0:
+			t.Errorf("%s: unexpected errors: %v", tt.name, errs)
+		case tt.err != "" && len(errs) != 1:
+			t.Errorf("%s: got errors %v, want %q", tt.name, errs, tt.err)
+		case tt.err != "" && !strings.Contains(errs[0].Error(), tt.err):
+			t.Errorf("%s: got error %v, want %q", tt.name, errs[0], tt.err)
+		}
+	}
+}
+
+func TestEnumValueBounds(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		err   string
+	}{
+		{value: "2147483647"},
+		{value: "-2147483648"},
+		{value: "3000000000", err: "value 3000000000 for big too large (maximum is 2147483647)"},
+		{value: "-3000000000", err: "value -3000000000 for big too small (minimum is -2147483648)"},
+	} {
+		mod := `
+module test {
+  prefix test;
+  namespace urn:test;
+
+  leaf l {
+    type enumeration {
+      enum big { value ` + tt.value + `; }
+    }
+  }
+}
+`
+		ms := NewModules()
+		if err := ms.Parse(mod, "test.yang"); err != nil {
+			t.Fatalf("%s: %v", tt.value, err)
+		}
+		errs := ms.Process()
+		switch {
+		case tt.err == "" && len(errs) > 0:
+			t.Errorf("%s: unexpected errors: %v", tt.value, errs)
+		case tt.err != "" && len(errs) != 1:
+			t.Errorf("%s: got errors %v, want %q", tt.value, errs, tt.err)
+		case tt.err != "" && !strings.Contains(errs[0].Error(), tt.err):
+			t.Errorf("%s: got error %v, want %q", tt.value, errs[0], tt.err)
+		}
+	}
+}
+
+func TestEnumDeclaredNames(t *testing.T) {
+	e := NewEnumType()
+	for _, n := range []string{"up", "down", "testing"} {
+		if err := e.SetNext(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := e.DeclaredNames()
+	if want := []string{"up", "down", "testing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if want := []string{"down", "testing", "up"}; !reflect.DeepEqual(e.Names(), want) {
+		t.Errorf("Names: got %v, want %v", e.Names(), want)
+	}
+}
+
+func TestResolveTypePrefix(t *testing.T) {
+	ms := NewModules()
+	for name, src := range map[string]string{
+		"inet.yang": `
+module inet {
+  namespace "urn:inet";
+  prefix "inet";
+  typedef ipv4-address { type string; }
+}
+`,
+		"host.yang": `
+module host {
+  namespace "urn:host";
+  prefix "h";
+  import inet { prefix i; }
+  include host-types;
+
+  typedef port-number { type uint16; }
+  leaf address { type i:ipv4-address; }
+  leaf port { type port-number; }
+  leaf name { type string; }
+  leaf vlan { type vlan-id; }
+}
+`,
+		"host-types.yang": `
+submodule host-types {
+  belongs-to host { prefix "h"; }
+  typedef vlan-id { type uint16; }
+}
+`,
+	} {
+		if err := ms.Parse(src, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	host, errs := ms.GetModule("host")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	for _, tt := range []struct {
+		leaf   string
+		module string
+		name   string
+	}{
+		{"address", "inet", "ipv4-address"},
+		{"port", "host", "port-number"},
+		{"name", "", "string"},
+		{"vlan", "host", "vlan-id"},
+	} {
+		module, name := ResolveTypePrefix(host.Dir[tt.leaf].Type)
+		if module != tt.module || name != tt.name {
+			t.Errorf("%s: got %s, %s, want %s, %s", tt.leaf, module, name, tt.module, tt.name)
+		}
+	}
+}
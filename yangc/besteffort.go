@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// bestEffort generates what it can of modules with imports or types that
+// cannot be found, warning about them rather than failing.
+var bestEffort bool
+
+func init() {
+	mainCmd.PersistentFlags().BoolVar(&bestEffort, "best-effort", false, "warn about unresolved imports and types and generate what can be, rather than failing")
+}
+
+// recoverable returns the errors in errs that --best-effort recovers from
+// and the fatal rest.
+func recoverable(errs []error) (recovered, fatal []error) {
+	for _, err := range errs {
+		if _, ok := err.(*yang.UnresolvedError); ok && bestEffort {
+			recovered = append(recovered, err)
+		} else {
+			fatal = append(fatal, err)
+		}
+	}
+	return recovered, fatal
+}
+
+// unresolvedType is the placeholder type of leaves whose type could not be
+// found.  Its kind is unknown to every generator, which falls back as
+// selected by --on-unknown-type.
+var unresolvedType = &yang.YangType{Name: "unresolved", Kind: yang.Ynone}
+
+// fillUnresolved gives each leaf and leaf-list in e without a type the
+// unresolvedType placeholder.
+func fillUnresolved(e *yang.Entry) {
+	if e.Kind == yang.LeafEntry && e.Type == nil {
+		e.Type = unresolvedType
+	}
+	if e.RPC != nil {
+		for _, se := range []*yang.Entry{e.RPC.Input, e.RPC.Output} {
+			if se != nil {
+				fillUnresolved(se)
+			}
+		}
+	}
+	for _, se := range e.Dir {
+		fillUnresolved(se)
+	}
+}
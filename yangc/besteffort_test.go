@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+const partialModule = `
+module partial {
+  namespace "urn:partial";
+  prefix "p";
+
+  import missing-types { prefix mt; }
+
+  container port {
+    leaf name { type string; }
+    leaf speed { type mt:speed; }
+    leaf mtu { type uint16; }
+  }
+}
+`
+
+func TestBestEffort(t *testing.T) {
+	defer func(b bool, c map[string]int, w io.Writer) {
+		bestEffort, diagnosticCounts, diagOut = b, c, w
+	}(bestEffort, diagnosticCounts, diagOut)
+	diagnosticCounts = map[string]int{}
+	var diags bytes.Buffer
+	diagOut = &diags
+
+	for _, be := range []bool{false, true} {
+		bestEffort = be
+		ms := yang.NewModules()
+		if err := ms.Parse(partialModule, "partial.yang"); err != nil {
+			t.Fatal(err)
+		}
+		entries, errs := moduleEntries(ms)
+		if !be {
+			if len(errs) == 0 {
+				t.Errorf("unresolved import compiled without --best-effort")
+			}
+			continue
+		}
+		if len(errs) > 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		var buf bytes.Buffer
+		doProto(&buf, entries)
+		out := buf.String()
+		for _, want := range []string{
+			"string name = ",
+			"uint32 mtu = ",
+			"bytes speed = ",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("missing %q in:\n%s", want, out)
+			}
+		}
+		if !strings.Contains(diags.String(), "no such module: missing-types") {
+			t.Errorf("unresolved import not warned about:\n%s", diags.String())
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// bitMasks stores bits leaves with positions up to 63 as uint64 and
+// defines a mask for each bit.
+var bitMasks bool
+
+// fitsBitMask returns true if all the bits of st fit in a uint64.
+func fitsBitMask(st *yang.YangType) bool {
+	values := st.Bit.Values()
+	return len(values) > 0 && values[len(values)-1] <= 63
+}
+
+// writeBitMasks writes a #define named prefix_BIT_NAME for each bit of st,
+// holding the mask of the bit in a uint64.
+func (pf *protofile) writeBitMasks(w io.Writer, prefix string, st *yang.YangType) {
+	names := map[int64][]string{}
+	for n, v := range st.Bit.NameMap() {
+		names[v] = append(names[v], n)
+	}
+	for _, v := range dedup(st.Bit.Values()) {
+		ns := names[v]
+		sort.Strings(ns)
+		for _, n := range ns {
+			fmt.Fprintf(w, "#define %s_BIT_%s 0x%016x\n", prefix, strings.ToUpper(pf.fieldName(n)), uint64(1)<<uint(v))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHeaderBitMasks(t *testing.T) {
+	defer func(b bool) { bitMasks = b }(bitMasks)
+	bitMasks = true
+
+	entries := compileString(t, "flags.yang", `
+module flags {
+  namespace "urn:flags";
+  prefix "f";
+
+  container port {
+    leaf state {
+      type bits {
+        bit up { position 0; }
+        bit running { position 5; }
+        bit far { position 40; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doTable(&buf, entries)
+	out := buf.String()
+	for _, want := range []string{
+		"#define PORT_STATE_BIT_UP 0x0000000000000001\n",
+		"#define PORT_STATE_BIT_RUNNING 0x0000000000000020\n",
+		"#define PORT_STATE_BIT_FAR 0x0000010000000000\n",
+		"uint64 state = 1;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	// The defines are at file scope, not between the fields.
+	if i, j := strings.Index(out, "#define PORT_STATE_BIT_FAR"), strings.Index(out, "struct Port {"); i < 0 || j < 0 || i > j {
+		t.Errorf("bit masks not written before the struct:\n%s", out)
+	}
+	if strings.Contains(out, "INLINE-bits") {
+		t.Errorf("bits not stored as uint64:\n%s", out)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/indent"
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var capnpCmd = &cobra.Command{
+		Use:   "capnp",
+		Short: "yangc with Cap'n Proto schema format",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doCapnp(os.Stdout, entries)
+		},
+	}
+	mainCmd.AddCommand(capnpCmd)
+}
+
+// kind2capnp maps base yang types to Cap'n Proto types.
+var kind2capnp = map[yang.TypeKind]string{
+	yang.Yint8:   "Int8",
+	yang.Yint16:  "Int16",
+	yang.Yint32:  "Int32",
+	yang.Yint64:  "Int64",
+	yang.Yuint8:  "UInt8",
+	yang.Yuint16: "UInt16",
+	yang.Yuint32: "UInt32",
+	yang.Yuint64: "UInt64",
+
+	yang.Ybinary:             "Data",   // arbitrary data
+	yang.Ybits:               "UInt64", // set of bits or flags
+	yang.Ybool:               "Bool",   // true or false
+	yang.Ydecimal64:          "Text",   // signed decimal number
+	yang.Yempty:              "Void",   // value is its presense
+	yang.Yenum:               "enum",   // handled inline
+	yang.Yidentityref:        "Text",   // reference to abstract identity
+	yang.YinstanceIdentifier: "Text",   // reference of a data tree node
+	yang.Yleafref:            "Text",   // reference to a leaf instance
+	yang.Ystring:             "Text",   // human readable string
+	yang.Yunion:              "union",  // handled inline
+}
+
+// doCapnp writes a Cap'n Proto schema for each module in entries to w.  The
+// module itself becomes the outermost struct.
+func doCapnp(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		if e == nil || len(e.Dir) == 0 {
+			continue // skip modules that have nothing in them
+		}
+		pf := &protofile{
+			fixedNames: map[string]string{},
+			messages:   map[string]*messageInfo{},
+		}
+		fmt.Fprintf(w, "# Automatically generated by yangc\n")
+		fmt.Fprintf(w, "# module %q\n", e.Name)
+		fmt.Fprintf(w, "@0x%016x;\n", capnpID(e))
+		pf.printStruct(w, e)
+	}
+}
+
+// capnpID returns the Cap'n Proto file ID for the module e.  It is derived
+// from the module namespace, or name, so it is stable across runs.  File IDs
+// must have the high bit set.
+func capnpID(e *yang.Entry) uint64 {
+	id := e.Name
+	if ns := yang.ModuleNamespace(e); ns != "" {
+		id = ns
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return h.Sum64() | 1<<63
+}
+
+// capnpTypeName returns s as a Cap'n Proto type name.  Cap'n Proto does not
+// allow underscores in identifiers.
+func capnpTypeName(s string) string {
+	return strings.Replace(yang.CamelCase(s), "_", "", -1)
+}
+
+// capnpFieldName returns s as a Cap'n Proto field or enumerant name, which
+// must start with a lower case letter.
+func capnpFieldName(s string) string {
+	n := capnpTypeName(s)
+	switch {
+	case n == "":
+		return n
+	case n[0] >= 'A' && n[0] <= 'Z':
+		return string(n[0]+'a'-'A') + n[1:]
+	case n[0] >= '0' && n[0] <= '9':
+		return "x" + n
+	}
+	return n
+}
+
+// capnpOrdinals numbers the fields of a Cap'n Proto struct, which must run
+// contiguously from 0 in the order the fields are written.
+type capnpOrdinals int
+
+// next returns the ordinal of the next field.
+func (o *capnpOrdinals) next() int {
+	n := int(*o)
+	*o++
+	return n
+}
+
+// printStruct writes e, and the structs and enums of its children, to w as a
+// Cap'n Proto struct.
+func (pf *protofile) printStruct(w io.Writer, e *yang.Entry) {
+	if e.Description != "" {
+		fmt.Fprintln(indent.NewWriter(w, "# "), e.Description)
+	}
+	var ords capnpOrdinals
+	fmt.Fprintf(w, "struct %s {\n", capnpTypeName(e.Name)) // matching brace }
+	pf.printStructFields(indent.NewWriter(w, indentString), e, &ords)
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintln(w, "}")
+}
+
+// printStructFields writes the fields for the children of e to w.  The
+// children of a choice are fields of the struct containing the choice.
+func (pf *protofile) printStructFields(w io.Writer, e *yang.Entry, ords *capnpOrdinals) {
+	for _, se := range children(e) {
+		if isChoice(se) {
+			pf.printStructFields(w, se, ords)
+			continue
+		}
+		name := capnpFieldName(se.Name)
+		isList := se.ListAttr != nil
+		var kind string
+		switch st := fieldType(se); {
+		case isDirectory(se):
+			pf.printStruct(w, se)
+			kind = capnpTypeName(se.Name)
+		case st.Kind == yang.Yenum:
+			kind = capnpTypeName(se.Name)
+			fmt.Fprintf(w, "enum %s {\n", kind) // matching brace }
+			// Enumerants are numbered from 0 in declaration
+			// order, Cap'n Proto has no explicit values.
+			for i, n := range st.Enum.DeclaredNames() {
+				fmt.Fprintf(w, "%s%s @%d;\n", indentString, capnpFieldName(n), i)
+			}
+			// { to match the brace below to keep brace matching working
+			fmt.Fprintln(w, "}")
+		case st.Kind == yang.Yunion:
+			types := capnpUnionTypes(se, st)
+			if len(types) == 1 {
+				kind = types[0]
+				break
+			}
+			if !isList {
+				// A named union is a group within this struct
+				// so its members are numbered with our fields.
+				fmt.Fprintf(w, "%s :union {\n", name) // matching brace }
+				for _, t := range types {
+					fmt.Fprintf(w, "%s%s%s @%d :%s;\n", indentString, name, t, ords.next(), t)
+				}
+				// { to match the brace below to keep brace matching working
+				fmt.Fprintln(w, "}")
+				continue
+			}
+			// A list of unions is a list of structs holding an
+			// anonymous union.
+			kind = capnpTypeName(se.Name) + "Value"
+			var uords capnpOrdinals
+			fmt.Fprintf(w, "struct %s {\n", kind)       // matching brace }
+			fmt.Fprintf(w, "%sunion {\n", indentString) // matching brace }
+			for _, t := range types {
+				fmt.Fprintf(w, "%s%s%s @%d :%s;\n", indentString+indentString, name, t, uords.next(), t)
+			}
+			// { to match the braces below to keep brace matching working
+			fmt.Fprintf(w, "%s}\n}\n", indentString)
+		default:
+			var reported bool
+			if kind, reported = lookupKind(kind2capnp, "Data", se, st.Kind); reported {
+				fmt.Fprintf(w, "# *WARNING* unknown type kind %s\n", st.Kind)
+			}
+		}
+		if isList {
+			kind = "List(" + kind + ")"
+		}
+		fmt.Fprintf(w, "%s @%d :%s;\n", name, ords.next(), kind)
+	}
+}
+
+// capnpUnionTypes returns the distinct Cap'n Proto types of the members of
+// the union t, the type of se, and of any unions within it.
+func capnpUnionTypes(se *yang.Entry, t *yang.YangType) []string {
+	var types []string
+	seen := map[string]bool{}
+	var add func(t *yang.YangType)
+	add = func(t *yang.YangType) {
+		for _, ut := range t.Type {
+			var k string
+			switch ut.Kind {
+			case yang.Yunion:
+				add(ut)
+				continue
+			case yang.Yenum:
+				k = "Text" // the name of the enum
+			default:
+				k, _ = lookupKind(kind2capnp, "Data", se, ut.Kind)
+			}
+			if !seen[k] {
+				seen[k] = true
+				types = append(types, k)
+			}
+		}
+	}
+	add(t)
+	return types
+}
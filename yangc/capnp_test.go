@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestCapnp(t *testing.T) {
+	e := compileString(t, "capnp.yang", `
+module capnp {
+  namespace "urn:capnp";
+  prefix "c";
+
+  container server {
+    leaf host-name { type string; }
+    leaf port { type uint16; }
+    leaf state {
+      type enumeration {
+        enum up;
+        enum down;
+      }
+    }
+    leaf address {
+      type union {
+        type uint32;
+        type string;
+      }
+    }
+    leaf-list alias { type string; }
+  }
+}
+`)[0]
+	var buf bytes.Buffer
+	doCapnp(&buf, []*yang.Entry{e})
+	out := buf.String()
+	if id := capnpID(e); id&(1<<63) == 0 {
+		t.Errorf("file id %#x does not have the high bit set", id)
+	}
+	for _, want := range []string{
+		"\n@0x",
+		"\nstruct Capnp {\n",
+		"\n  struct Server {\n",
+		"\n    hostName @0 :Text;\n",
+		"\n    port @1 :UInt16;\n",
+		"\n    enum State {\n      up @0;\n      down @1;\n    }\n    state @2 :State;\n",
+		"\n    address :union {\n      addressUInt32 @3 :UInt32;\n      addressText @4 :Text;\n    }\n",
+		"\n    alias @5 :List(Text);\n",
+		"\n  server @0 :Server;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestCapnpOrdinals(t *testing.T) {
+	e := compileString(t, "ordinals.yang", `
+module ordinals {
+  namespace "urn:ordinals";
+  prefix "o";
+
+  container c {
+    leaf a { type string; }
+    choice transport {
+      leaf tcp { type uint16; }
+      leaf udp { type uint16; }
+    }
+    leaf addr {
+      type union {
+        type uint32;
+        type string;
+      }
+    }
+    leaf-list values {
+      type union {
+        type int8;
+        type string;
+      }
+    }
+    leaf z { type string; }
+    leaf state {
+      type enumeration {
+        enum up { value 3; }
+        enum down { value 7; }
+      }
+    }
+  }
+}
+`)[0]
+	var buf bytes.Buffer
+	doCapnp(&buf, []*yang.Entry{e})
+	out := buf.String()
+	i := strings.Index(out, "struct C {")
+	if i < 0 {
+		t.Fatalf("no struct C in output:\n%s", out)
+	}
+	// The fields of C, leaving out those of the nested struct and enum.
+	var got []string
+	var nested []bool // for each open brace, whether it opens a struct or enum
+	inner := 0
+Lines:
+	for _, l := range strings.Split(out[i:], "\n")[1:] {
+		l = strings.TrimSpace(l)
+		switch {
+		case strings.HasSuffix(l, "{"):
+			n := strings.HasPrefix(l, "struct ") || strings.HasPrefix(l, "enum ")
+			if n {
+				inner++
+			}
+			nested = append(nested, n)
+		case l == "}" && len(nested) == 0:
+			break Lines
+		case l == "}":
+			if nested[len(nested)-1] {
+				inner--
+			}
+			nested = nested[:len(nested)-1]
+		case inner == 0 && strings.Contains(l, " @"):
+			got = append(got, l[strings.Index(l, "@"):strings.Index(l, " :")])
+		}
+	}
+	if want := "@0 @1 @2 @3 @4 @5 @6 @7"; strings.Join(got, " ") != want {
+		t.Errorf("got ordinals %v, want %s:\n%s", got, want, out)
+	}
+	for _, want := range []string{
+		"      up @0;\n      down @1;\n",
+		"valuesInt8 @0 :Int8;\n",
+		"valuesText @1 :Text;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "github.com/paranpen/yangc/pkg/yang"
+
+// isDeprecated returns true if the node of e has a status of deprecated or
+// obsolete.  Both are marked as deprecated in the generated code.
+func isDeprecated(e *yang.Entry) bool {
+	switch nodeValue(e, "Status") {
+	case "deprecated", "obsolete":
+		return true
+	}
+	return false
+}
+
+// protoDeprecated returns the field option marking the proto field for e as
+// deprecated, or "" if e is not deprecated.
+func protoDeprecated(e *yang.Entry) string {
+	if isDeprecated(e) {
+		return " [deprecated = true]"
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+const deprecatedModule = `
+module dep {
+  namespace "urn:dep";
+  prefix "d";
+
+  list port {
+    key id;
+    leaf id { type uint32; }
+    leaf speed {
+      type uint32;
+      status deprecated;
+    }
+  }
+}
+`
+
+func TestDeprecated(t *testing.T) {
+	e := compileString(t, "dep.yang", deprecatedModule)[0]
+	for _, tt := range []struct {
+		name string
+		gen  func(io.Writer, []*yang.Entry)
+		want string
+		not  string
+	}{
+		{"header", doHeader, "  // DEPRECATED\nuint32 speed = ", "// DEPRECATED\nuint32 id = "},
+		{"proto", doProto, " speed = 2 [deprecated = true];", " id = 1 [deprecated"},
+		{"typescript", doTypeScript, "  /** @deprecated */\n  speed?: number;\n", "/** @deprecated */\n  id:"},
+	} {
+		var buf bytes.Buffer
+		tt.gen(&buf, []*yang.Entry{e})
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("%s: missing %q in output:\n%s", tt.name, tt.want, buf.String())
+		}
+		if strings.Contains(buf.String(), tt.not) {
+			t.Errorf("%s: unexpected %q in output:\n%s", tt.name, tt.not, buf.String())
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// errorFormat is how compile errors are written, either "text" or "json".
+var errorFormat string
+
+// maxErrors is the number of errors written before the rest are only
+// counted, or 0 for no limit.
+var maxErrors = 20
+
+func init() {
+	mainCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "text", "format of compile errors: text or json (one object per line)")
+	mainCmd.PersistentFlags().IntVar(&maxErrors, "max-errors", 20, "stop reporting errors after this many, 0 for no limit")
+}
+
+// checkErrorFormat returns an error if --error-format is not a known format.
+func checkErrorFormat() error {
+	switch errorFormat {
+	case "text", "json":
+		return nil
+	}
+	return fmt.Errorf("invalid --error-format %q: want text or json", errorFormat)
+}
+
+// A diagnostic is a compile error as written by --error-format json.
+type diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// locationRE matches the location yang.Source prefixes errors with, either
+// "file:line:col: " or "line line:col: ".
+var locationRE = regexp.MustCompile(`(?s)^(?:line |(.*?):)(\d+):(\d+): (.*)$`)
+
+// newDiagnostic returns err as a diagnostic of the given severity.  The
+// location is taken from the start of the error message, if present.
+func newDiagnostic(severity string, err error) diagnostic {
+	d := diagnostic{
+		Severity: severity,
+		Message:  err.Error(),
+	}
+	if m := locationRE.FindStringSubmatch(d.Message); m != nil {
+		d.File = m[1]
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		d.Message = m[4]
+	}
+	return d
+}
+
+// diagOut is where warnings, errors and their summary are written.
+var diagOut io.Writer = os.Stderr
+
+// diagnosticCounts counts the diagnostics written by severity.
+var diagnosticCounts = map[string]int{}
+
+// exit makes testing of exitWithSummary easier.
+var exit = os.Exit
+
+// warn writes the warning err to diagOut in the format selected by
+// --error-format.
+func warn(err error) {
+	writeDiagnostic(diagOut, "warning", err)
+}
+
+// reportError writes the error err to diagOut in the format selected by
+// --error-format.
+func reportError(err error) {
+	writeError(diagOut, err)
+}
+
+// reportErrors reports each of errs.
+func reportErrors(errs []error) {
+	for _, err := range errs {
+		reportError(err)
+	}
+}
+
+// writeError writes err to w in the format selected by --error-format.
+func writeError(w io.Writer, err error) {
+	writeDiagnostic(w, "error", err)
+}
+
+// writeDiagnostic writes err with severity to w in the format selected by
+// --error-format.  In text format only warnings are labeled.  Errors past
+// --max-errors are counted but not written, exitWithSummary notes how many
+// more there were.
+func writeDiagnostic(w io.Writer, severity string, err error) {
+	diagnosticCounts[severity]++
+	if severity == "error" && maxErrors > 0 && diagnosticCounts[severity] > maxErrors {
+		return
+	}
+	if errorFormat != "json" {
+		if severity != "error" {
+			fmt.Fprintf(w, "%s: ", severity)
+		}
+		fmt.Fprintln(w, err)
+		return
+	}
+	b, jerr := json.Marshal(newDiagnostic(severity, err))
+	if jerr != nil {
+		fmt.Fprintln(w, err)
+		return
+	}
+	fmt.Fprintf(w, "%s\n", b)
+}
+
+// diagnosticSummary returns a line summarizing the diagnostics written so
+// far and the exit status they call for: 2 if there were errors, 1 if there
+// were only warnings and --strict is set, otherwise 0.
+func diagnosticSummary() (string, int) {
+	errs, warnings := diagnosticCounts["error"], diagnosticCounts["warning"]
+	summary := fmt.Sprintf("yangc: %d %s, %d %s", errs, plural(errs, "error"), warnings, plural(warnings, "warning"))
+	switch {
+	case errs > 0:
+		return summary, 2
+	case warnings > 0 && strict:
+		return summary, 1
+	}
+	return summary, 0
+}
+
+// plural returns noun, made plural unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
+
+// exitWithSummary writes the diagnostic summary to diagOut, preceded by a
+// note of the errors left out by --max-errors, unless no diagnostics were
+// written or they are written as JSON, and exits with the status returned
+// by diagnosticSummary.
+func exitWithSummary() {
+	summary, code := diagnosticSummary()
+	if len(diagnosticCounts) > 0 && errorFormat != "json" {
+		if more := diagnosticCounts["error"] - maxErrors; maxErrors > 0 && more > 0 {
+			fmt.Fprintf(diagOut, "... and %d more %s\n", more, plural(more, "error"))
+		}
+		fmt.Fprintln(diagOut, summary)
+	}
+	exit(code)
+}
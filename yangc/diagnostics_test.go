@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestErrorFormatJSON(t *testing.T) {
+	defer func(s string) { errorFormat = s }(errorFormat)
+
+	ms := yang.NewModules()
+	if err := ms.Parse(`
+module bad {
+  namespace "urn:bad";
+  prefix "b";
+
+  leaf l { type no-such-type; }
+}
+`, "bad.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs := ms.Process()
+	if len(errs) == 0 {
+		t.Fatal("no errors for an unknown type")
+	}
+
+	errorFormat = "json"
+	var buf bytes.Buffer
+	writeError(&buf, errs[0])
+	var d diagnostic
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		t.Fatalf("%q: %v", buf.String(), err)
+	}
+	if d.File != "bad.yang" || d.Line != 6 || d.Column != 12 || d.Severity != "error" || d.Message != "unknown type: b:no-such-type" {
+		t.Errorf("got %+v", d)
+	}
+
+	errorFormat = "text"
+	buf.Reset()
+	writeError(&buf, errors.New("plain"))
+	if got, want := buf.String(), "plain\n"; got != want {
+		t.Errorf("text format got %q, want %q", got, want)
+	}
+}
+
+func TestNewDiagnostic(t *testing.T) {
+	for _, tt := range []struct {
+		err  string
+		want diagnostic
+	}{
+		{"a.yang:3:4: oops", diagnostic{"a.yang", 3, 4, "error", "oops"}},
+		{"line 3:4: oops", diagnostic{"", 3, 4, "error", "oops"}},
+		{"no location", diagnostic{"", 0, 0, "error", "no location"}},
+	} {
+		if got := newDiagnostic("error", errors.New(tt.err)); got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDiagnosticSummary(t *testing.T) {
+	defer func(c map[string]int, w io.Writer, s bool, f func(int)) {
+		diagnosticCounts, diagOut, strict, exit = c, w, s, f
+	}(diagnosticCounts, diagOut, strict, exit)
+	var out bytes.Buffer
+	diagOut = &out
+	code := -1
+	exit = func(c int) { code = c }
+
+	entries := compileString(t, "mixed.yang", `
+module mixed {
+  namespace "urn:mixed";
+  prefix "m";
+
+  container flags {
+    leaf wide {
+      type bits {
+        bit low { position 0; }
+        bit high { position 70; }
+      }
+    }
+    leaf a { type string; must "a[ = b"; }
+    leaf b { type string; must "(b"; }
+  }
+}
+`)
+	for _, tt := range []struct {
+		strict bool
+		errs   []error
+		want   string
+		code   int
+	}{
+		{false, nil, "yangc: 0 errors, 1 warning\n", 0},
+		{true, nil, "yangc: 0 errors, 1 warning\n", 1},
+		{false, doValidate(entries), "yangc: 2 errors, 1 warning\n", 2},
+	} {
+		diagnosticCounts = map[string]int{}
+		strict = tt.strict
+		out.Reset()
+		code = -1
+		pf := &protofile{
+			fixedNames: map[string]string{},
+			messages:   map[string]*messageInfo{},
+		}
+		pf.printProto(ioutil.Discard, entries[0])
+		for _, err := range tt.errs {
+			reportError(err)
+		}
+		exitWithSummary()
+		if !strings.HasSuffix(out.String(), tt.want) {
+			t.Errorf("got:\n%s\nwant summary %q", out.String(), tt.want)
+		}
+		if code != tt.code {
+			t.Errorf("got exit status %d, want %d", code, tt.code)
+		}
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	defer func(c map[string]int, w io.Writer, n int, f func(int)) {
+		diagnosticCounts, diagOut, maxErrors, exit = c, w, n, f
+	}(diagnosticCounts, diagOut, maxErrors, exit)
+	var out bytes.Buffer
+	diagOut = &out
+	diagnosticCounts = map[string]int{}
+	exit = func(int) {}
+	maxErrors = 5
+
+	var src strings.Builder
+	src.WriteString(`module broken { namespace "urn:broken"; prefix "b";`)
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(&src, " leaf l%d { type no-such-type; }", i)
+	}
+	src.WriteString(" }")
+	ms := yang.NewModules()
+	if err := ms.Parse(src.String(), "broken.yang"); err != nil {
+		t.Fatal(err)
+	}
+	_, errs := moduleEntries(ms)
+	if len(errs) != 12 {
+		t.Fatalf("got %d errors, want 12: %v", len(errs), errs)
+	}
+	exitIfError(errs)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("got %d lines, want 5 errors, the note and the summary:\n%s", len(lines), out.String())
+	}
+	if want := "... and 7 more errors"; lines[5] != want {
+		t.Errorf("got %q, want %q", lines[5], want)
+	}
+	if want := "yangc: 12 errors, 0 warnings"; lines[6] != want {
+		t.Errorf("got %q, want %q", lines[6], want)
+	}
+	// Errors reported one at a time, as by the generators, are capped too.
+	out.Reset()
+	diagnosticCounts = map[string]int{}
+	for i := 0; i < 8; i++ {
+		reportError(fmt.Errorf("error %d", i))
+	}
+	exitWithSummary()
+	lines = strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if want := []string{"error 0", "error 1", "error 2", "error 3", "error 4", "... and 3 more errors", "yangc: 8 errors, 0 warnings"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
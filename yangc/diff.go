@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffOld string
+	diffNew string
+)
+
+func init() {
+	var diffCmd = &cobra.Command{
+		Use:   "diff",
+		Short: "report the nodes added, removed or changed between two versions of a schema",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if diffOld == "" || diffNew == "" {
+				return errors.New("diff needs both --old and --new")
+			}
+			doDiff(os.Stdout, doCompile(diffOld), doCompile(diffNew))
+			return nil
+		},
+	}
+	diffCmd.Flags().StringVar(&diffOld, "old", "", "yang file of the old version")
+	diffCmd.Flags().StringVar(&diffNew, "new", "", "yang file of the new version")
+	mainCmd.AddCommand(diffCmd)
+}
+
+// doDiff writes the differences between the schema trees oldEntries and
+// newEntries to w, one node per line sorted by path: +path for an added
+// node, -path for a removed node and ~path: old -> new for a node whose
+// type changed.
+func doDiff(w io.Writer, oldEntries, newEntries []*yang.Entry) {
+	before, after := nodeTypes(oldEntries), nodeTypes(newEntries)
+	paths := make([]string, 0, len(before)+len(after))
+	for p := range before {
+		paths = append(paths, p)
+	}
+	for p := range after {
+		if _, ok := before[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		b, inOld := before[p]
+		a, inNew := after[p]
+		switch {
+		case !inOld:
+			fmt.Fprintf(w, "+%s\n", p)
+		case !inNew:
+			fmt.Fprintf(w, "-%s\n", p)
+		case a != b:
+			fmt.Fprintf(w, "~%s: %s -> %s\n", p, b, a)
+		}
+	}
+}
+
+// nodeTypes returns the type of every node in entries keyed by its path.
+// The type of a leaf is the name of its type, other nodes are typed by the
+// statement that defined them, such as container.
+func nodeTypes(entries []*yang.Entry) map[string]string {
+	types := map[string]string{}
+	var walk func(e *yang.Entry)
+	walk = func(e *yang.Entry) {
+		if e == nil {
+			return
+		}
+		if e.Type != nil {
+			types[e.FullPath()] = e.Type.Name
+		} else {
+			types[e.FullPath()] = kindName(e)
+		}
+		if e.RPC != nil {
+			walk(e.RPC.Input)
+			walk(e.RPC.Output)
+		}
+		for _, se := range sortedDir(e) {
+			walk(se)
+		}
+	}
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		for _, se := range sortedDir(e) {
+			walk(se)
+		}
+	}
+	return types
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := compileString(t, "sys.yang", `
+module sys {
+  namespace "urn:sys";
+  prefix "s";
+
+  container system {
+    leaf hostname { type string; }
+    leaf mtu { type uint32; }
+    leaf contact { type string; }
+  }
+}
+`)
+	cur := compileString(t, "sys.yang", `
+module sys {
+  namespace "urn:sys";
+  prefix "s";
+
+  container system {
+    leaf hostname { type string; }
+    leaf mtu { type string; }
+    leaf location { type string; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doDiff(&buf, old, cur)
+	want := `-/sys:system/contact
++/sys:system/location
+~/sys:system/mtu: uint32 -> string
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+var (
+	failOnUnknownExt bool
+	allowedExts      []string
+	emitExtensions   bool
+)
+
+// knownExtensions are the extension statements understood by the
+// generators, as prefix:name or, for any prefix, name.
+var knownExtensions = map[string]bool{
+	"grpc:stream": true,
+	"mount-point": true, // ietf-yang-schema-mount, see mountPoint
+}
+
+func init() {
+	mainCmd.PersistentFlags().BoolVar(&failOnUnknownExt, "fail-on-unknown-extension", false, "fail if a node carries an unknown extension statement")
+	mainCmd.PersistentFlags().BoolVar(&emitExtensions, "emit-extensions", false, "write the extension statements of each node as // @ext module:name \"argument\" comments")
+	mainCmd.PersistentFlags().StringSliceVar(&allowedExts, "allow-extension", nil, "extension statements (prefix:name or name) accepted by --fail-on-unknown-extension")
+}
+
+// extensionAllowed returns true if the extension keyword kw is either known
+// to the generators or was allowed with --allow-extension.
+func extensionAllowed(kw string) bool {
+	name := kw
+	if i := strings.Index(kw, ":"); i >= 0 {
+		name = kw[i+1:]
+	}
+	if knownExtensions[kw] || knownExtensions[name] {
+		return true
+	}
+	for _, a := range allowedExts {
+		if a == kw || a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mountPoint returns the label of the ietf-yang-schema-mount mount-point
+// extension on e, or "" if e is not a mount point.  The schema mounted at a
+// mount point is not resolved, the generators only annotate it.
+func mountPoint(e *yang.Entry) string {
+	for _, ext := range e.Exts {
+		kw := ext.Kind()
+		if kw == "mount-point" || strings.HasSuffix(kw, ":mount-point") {
+			return ext.NName()
+		}
+	}
+	return ""
+}
+
+// writeMountPoint writes a placeholder comment, prefixed by ind, to w if e
+// is a mount point.
+func writeMountPoint(w io.Writer, ind string, e *yang.Entry) {
+	if label := mountPoint(e); label != "" {
+		fmt.Fprintf(w, "%s// mount-point: %s\n", ind, label)
+	}
+}
+
+// writeExtensions writes each extension statement on e to w, prefixed by
+// ind, as a structured comment if --emit-extensions is set.  The comment
+// names the module defining the extension rather than the local prefix:
+//
+//	// @ext module:name "argument"
+func writeExtensions(w io.Writer, ind string, e *yang.Entry) {
+	if !emitExtensions {
+		return
+	}
+	for _, ext := range e.Exts {
+		fmt.Fprintf(w, "%s// @ext %s", ind, extensionName(e, ext.Kind()))
+		if arg, ok := ext.Arg(); ok {
+			fmt.Fprintf(w, " %q", arg)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// extensionName returns the extension keyword kw, found on e, with its
+// prefix replaced by the name of the module it refers to.  The keyword is
+// returned unchanged if the prefix cannot be resolved.
+func extensionName(e *yang.Entry, kw string) string {
+	i := strings.Index(kw, ":")
+	if i < 0 || e.Node == nil {
+		return kw
+	}
+	if m := yang.FindModuleByPrefix(e.Node, kw[:i]); m != nil {
+		return yang.ModuleName(m) + kw[i:]
+	}
+	return kw
+}
+
+// unknownExtensions returns an error for every extension statement found in
+// e and its descendants that is not allowed.
+func unknownExtensions(e *yang.Entry) []error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	for _, ext := range e.Exts {
+		if !extensionAllowed(ext.Kind()) {
+			errs = append(errs, fmt.Errorf("%s: unknown extension %s", yang.Source(ext), ext.Kind()))
+		}
+	}
+	if e.RPC != nil {
+		errs = append(errs, unknownExtensions(e.RPC.Input)...)
+		errs = append(errs, unknownExtensions(e.RPC.Output)...)
+	}
+	for _, se := range e.Dir {
+		errs = append(errs, unknownExtensions(se)...)
+	}
+	return errs
+}
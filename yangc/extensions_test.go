@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+const extensionModule = `
+module ext-test {
+  namespace "urn:ext-test";
+  prefix "et";
+
+  container system {
+    vendor:hidden;
+    leaf name {
+      type string;
+      vendor:secret "yes";
+    }
+  }
+}
+`
+
+func TestUnknownExtensions(t *testing.T) {
+	defer func(a []string) { allowedExts = a }(allowedExts)
+
+	e := compileString(t, "ext-test.yang", extensionModule)[0]
+	for _, tt := range []struct {
+		allow []string
+		want  int
+	}{
+		{want: 2},
+		{allow: []string{"vendor:hidden"}, want: 1},
+		{allow: []string{"hidden", "secret"}, want: 0},
+	} {
+		allowedExts = tt.allow
+		if errs := unknownExtensions(e); len(errs) != tt.want {
+			t.Errorf("allow %v: got errors %v, want %d", tt.allow, errs, tt.want)
+		}
+	}
+}
+
+func TestFailOnUnknownExtensionExit(t *testing.T) {
+	if os.Getenv("YANGC_TEST_EXIT") == "1" {
+		failOnUnknownExt = true
+		compileString(t, "ext-test.yang", extensionModule)
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=TestFailOnUnknownExtensionExit")
+	cmd.Env = append(os.Environ(), "YANGC_TEST_EXIT=1")
+	err := cmd.Run()
+	if e, ok := err.(*exec.ExitError); !ok || e.Success() {
+		t.Fatalf("got %v, want non-zero exit status", err)
+	}
+}
+
+func TestMountPoint(t *testing.T) {
+	entries := compileStrings(t, map[string]string{
+		"ietf-yang-schema-mount": `
+module ietf-yang-schema-mount {
+  namespace "urn:ietf:params:xml:ns:yang:ietf-yang-schema-mount";
+  prefix "yangmnt";
+  extension mount-point { argument label; }
+}
+`,
+		"device": `
+module device {
+  namespace "urn:device";
+  prefix "dev";
+  import ietf-yang-schema-mount { prefix "yangmnt"; }
+
+  container logical-network-elements {
+    list logical-network-element {
+      key "name";
+      leaf name { type string; }
+      container root {
+        yangmnt:mount-point "root";
+      }
+    }
+  }
+}
+`,
+	})
+	var device *yang.Entry
+	for _, e := range entries {
+		if e.Name == "device" {
+			device = e
+		}
+	}
+	if device == nil {
+		t.Fatal("module device not compiled")
+	}
+	if errs := unknownExtensions(device); len(errs) != 0 {
+		t.Errorf("mount-point reported as unknown: %v", errs)
+	}
+	for name, gen := range map[string]func(io.Writer, []*yang.Entry){"proto": doProto, "header": doHeader} {
+		var buf bytes.Buffer
+		gen(&buf, []*yang.Entry{device})
+		if want := "// mount-point: root\n"; !strings.Contains(buf.String(), want) {
+			t.Errorf("%s: missing %q in output:\n%s", name, want, buf.String())
+		}
+	}
+}
+
+func TestEmitExtensions(t *testing.T) {
+	defer func(b bool) { emitExtensions = b }(emitExtensions)
+	emitExtensions = true
+
+	entries := compileStrings(t, map[string]string{
+		"example-ext": `
+module example-ext {
+  namespace "urn:example-ext";
+  prefix "ex";
+  extension sensitive;
+  extension units-scale { argument scale; }
+}
+`,
+		"device": `
+module device {
+  namespace "urn:device";
+  prefix "dev";
+  import example-ext { prefix "x"; }
+
+  container system {
+    x:units-scale "milli";
+    leaf password {
+      type string;
+      x:sensitive;
+      x:units-scale "none";
+    }
+  }
+}
+`,
+	})
+	var device []*yang.Entry
+	for _, e := range entries {
+		if e.Name == "device" {
+			device = append(device, e)
+		}
+	}
+	for name, gen := range map[string]func(io.Writer, []*yang.Entry){"proto": doProto, "header": doHeader} {
+		var buf bytes.Buffer
+		gen(&buf, device)
+		for _, want := range []string{
+			"  // @ext example-ext:units-scale \"milli\"\n",
+			"  // @ext example-ext:sensitive\n  // @ext example-ext:units-scale \"none\"\n",
+		} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s: missing %q in output:\n%s", name, want, buf.String())
+			}
+		}
+	}
+}
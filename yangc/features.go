@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var featuresCmd = &cobra.Command{
+		Use:   "features",
+		Short: "list the features declared by the modules",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doFeatures(os.Stdout, entries)
+		},
+	}
+	mainCmd.AddCommand(featuresCmd)
+}
+
+// doFeatures writes each feature declared by the modules of entries to w,
+// one per line as module:feature.  A feature depending on other features
+// is followed by its if-feature expressions, all of which must hold.
+func doFeatures(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		m, ok := e.Node.(*yang.Module)
+		if !ok {
+			continue
+		}
+		for _, f := range m.Feature {
+			fmt.Fprintf(w, "%s:%s", e.Name, f.Name)
+			if len(f.IfFeature) > 0 {
+				deps := make([]string, len(f.IfFeature))
+				for i, v := range f.IfFeature {
+					deps[i] = v.Name
+				}
+				fmt.Fprintf(w, " (if-feature %s)", strings.Join(deps, " and "))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	entries := compileString(t, "router.yang", `
+module router {
+  namespace "urn:router";
+  prefix "r";
+
+  feature routing;
+  feature bgp {
+    if-feature routing;
+  }
+  container config { leaf name { type string; } }
+}
+`)
+	var buf bytes.Buffer
+	doFeatures(&buf, entries)
+	want := `router:routing
+router:bgp (if-feature routing)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// sortFields is the order in which the children of a node are generated,
+// either "schema" (declaration order) or "name".  When not set each output
+// keeps its own order: the tree and the flattened messages are in name
+// order, everything else in schema order.
+var sortFields string
+
+func init() {
+	mainCmd.PersistentFlags().StringVar(&sortFields, "sort-fields", "", "order of generated fields: schema (declaration order) or name (default name for tree, otherwise schema)")
+}
+
+// checkSortFields returns an error if --sort-fields is not a known order.
+func checkSortFields() error {
+	switch sortFields {
+	case "", "schema", "name":
+		return nil
+	}
+	return fmt.Errorf("invalid --sort-fields %q: want schema or name", sortFields)
+}
+
+// sortEntries sorts es in the order selected by --sort-fields, schema order
+// by default.
+func sortEntries(es []*yang.Entry) {
+	orderEntries(es, "schema")
+}
+
+// orderEntries sorts es in the order selected by --sort-fields, or in order
+// def if it is not set.  In schema order entries are sorted by where they
+// were declared.  Entries from a grouping are placed where the grouping was
+// used.  Entries with no known location are placed last.  Ties are broken by
+// name.
+func orderEntries(es []*yang.Entry, def string) {
+	order := sortFields
+	if order == "" {
+		order = def
+	}
+	if order == "name" {
+		sort.Slice(es, func(i, j int) bool { return es[i].Name < es[j].Name })
+		return
+	}
+	sort.SliceStable(es, func(i, j int) bool {
+		fi, li, ci, oki := position(es[i])
+		fj, lj, cj, okj := position(es[j])
+		switch {
+		case oki != okj:
+			return oki
+		case fi != fj:
+			return fi < fj
+		case li != lj:
+			return li < lj
+		case ci != cj:
+			return ci < cj
+		}
+		return es[i].Name < es[j].Name
+	})
+}
+
+// position returns where the node of e was declared, or for a node from a
+// grouping, where the grouping was used by the parent of e.  ok is false if
+// the location is not known.
+func position(e *yang.Entry) (file string, line, col int, ok bool) {
+	if e.Node == nil {
+		return "", 0, 0, false
+	}
+	var n yang.Node = e.Node
+	if e.Parent != nil && e.Parent.Node != nil {
+		if u := usesNode(e.Node, e.Parent.Node, map[yang.Node]bool{}); u != nil {
+			n = u
+		}
+	}
+	s := n.Statement()
+	if s == nil {
+		return "", 0, 0, false
+	}
+	file, line, col = s.Position()
+	return file, line, col, line > 0
+}
+
+// usesNode returns the uses statement of parent through which n was added,
+// directly or by a uses nested in the grouping, or nil if n was not added by
+// a uses of parent.
+func usesNode(n, parent yang.Node, seen map[yang.Node]bool) *yang.Uses {
+	f := reflect.ValueOf(parent).Elem().FieldByName("Uses")
+	if !f.IsValid() {
+		return nil
+	}
+	uses, _ := f.Interface().([]*yang.Uses)
+	for _, u := range uses {
+		g := yang.FindGrouping(u, u.Name, map[string]bool{})
+		if g == nil || seen[g] {
+			continue
+		}
+		seen[g] = true
+		if isDescendant(n, g) || usesNode(n, g, seen) != nil {
+			return u
+		}
+	}
+	return nil
+}
+
+// isDescendant returns true if n is below the node p.
+func isDescendant(n, p yang.Node) bool {
+	for n = n.ParentNode(); n != nil; n = n.ParentNode() {
+		if n == p {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedDir returns the entries of e.Dir in the order selected by
+// --sort-fields, schema order by default.
+func sortedDir(e *yang.Entry) []*yang.Entry {
+	return orderedDir(e, "schema")
+}
+
+// nameSortedDir is like sortedDir but defaults to name order, which the tree
+// and the flattened messages have always used.
+func nameSortedDir(e *yang.Entry) []*yang.Entry {
+	return orderedDir(e, "name")
+}
+
+// orderedDir returns the entries of e.Dir sorted by orderEntries.
+func orderedDir(e *yang.Entry, def string) []*yang.Entry {
+	es := make([]*yang.Entry, 0, len(e.Dir))
+	for _, se := range e.Dir {
+		es = append(es, se)
+	}
+	orderEntries(es, def)
+	return es
+}
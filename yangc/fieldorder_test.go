@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestSortFields(t *testing.T) {
+	defer func(s string) { sortFields = s }(sortFields)
+
+	e := compileString(t, "order.yang", `
+module order {
+  namespace "urn:order";
+  prefix "o";
+
+  container c {
+    leaf zulu { type string; }
+    leaf alpha { type string; }
+    leaf mike { type string; }
+  }
+}
+`)[0]
+	for _, tt := range []struct {
+		order string
+		want  []string
+	}{
+		{"schema", []string{"zulu", "alpha", "mike"}},
+		{"name", []string{"alpha", "mike", "zulu"}},
+	} {
+		sortFields = tt.order
+		var got []string
+		for _, se := range children(e.Dir["c"]) {
+			got = append(got, se.Name)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: got order %v, want %v", tt.order, got, tt.want)
+		}
+
+		var buf bytes.Buffer
+		doProto(&buf, []*yang.Entry{e})
+		want := "  string " + tt.want[0] + " = 1;\n  string " + tt.want[1] + " = 2;\n  string " + tt.want[2] + " = 3;\n"
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s: missing %q in output:\n%s", tt.order, want, buf.String())
+		}
+	}
+
+	sortFields = "random"
+	if err := checkSortFields(); err == nil {
+		t.Errorf("checkSortFields accepted %q", sortFields)
+	}
+}
+
+func TestSortFieldsGrouping(t *testing.T) {
+	defer func(s string) { sortFields = s }(sortFields)
+	sortFields = ""
+
+	e := compileString(t, "grouping.yang", `
+module grouping {
+  namespace "urn:grouping";
+  prefix "g";
+
+  grouping inner {
+    leaf delta { type string; }
+  }
+  grouping outer {
+    leaf charlie { type string; }
+    uses inner;
+  }
+  container c {
+    leaf zulu { type string; }
+    uses outer;
+    leaf alpha { type string; }
+  }
+}
+`)[0]
+	var got []string
+	for _, se := range children(e.Dir["c"]) {
+		got = append(got, se.Name)
+	}
+	if want := "zulu charlie delta alpha"; strings.Join(got, " ") != want {
+		t.Errorf("got order %v, want %s", got, want)
+	}
+
+	// The tree stays in name order unless --sort-fields is given.
+	var buf bytes.Buffer
+	WriteTree(&buf, e.Dir["c"])
+	if i, j := strings.Index(buf.String(), "alpha"), strings.Index(buf.String(), "zulu"); i < 0 || j < i {
+		t.Errorf("tree not in name order:\n%s", buf.String())
+	}
+	sortFields = "schema"
+	buf.Reset()
+	WriteTree(&buf, e.Dir["c"])
+	if i, j := strings.Index(buf.String(), "alpha"), strings.Index(buf.String(), "zulu"); j < 0 || i < j {
+		t.Errorf("tree not in schema order:\n%s", buf.String())
+	}
+}
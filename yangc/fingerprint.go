@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var fingerprintCmd = &cobra.Command{
+		Use:   "fingerprint",
+		Short: "print a SHA-256 fingerprint of the structure of each module",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doFingerprint(os.Stdout, entries)
+		},
+	}
+	mainCmd.AddCommand(fingerprintCmd)
+}
+
+// doFingerprint writes the fingerprint of each module in entries to w, one
+// per line followed by the name of the module.
+func doFingerprint(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		if e != nil {
+			fmt.Fprintf(w, "%s  %s\n", fingerprint(e), e.Name)
+		}
+	}
+}
+
+// fingerprint returns the hex encoded SHA-256 of the canonical form of the
+// schema tree e.  Only the paths, kinds, types and constraints of the nodes
+// are part of the canonical form, so changes to descriptions, references or
+// the layout of the source do not change the fingerprint.
+func fingerprint(e *yang.Entry) string {
+	h := sha256.New()
+	writeCanonical(h, e)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// writeCanonical writes the canonical form of e and its descendants to w,
+// one node per line in path order.
+func writeCanonical(w io.Writer, e *yang.Entry) {
+	fmt.Fprintf(w, "%s %s config=%s", e.FullPath(), kindName(e), e.Config)
+	if v := nodeValue(e, "Mandatory"); v != "" {
+		fmt.Fprintf(w, " mandatory=%s", v)
+	}
+	if v := nodeValue(e, "When"); v != "" {
+		fmt.Fprintf(w, " when=%q", v)
+	}
+	for _, m := range musts(e) {
+		fmt.Fprintf(w, " must=%q", m.Name)
+	}
+	if e.Key != "" {
+		fmt.Fprintf(w, " key=%q", e.Key)
+	}
+	if la := e.ListAttr; la != nil {
+		for _, a := range []struct {
+			name string
+			v    *yang.Value
+		}{
+			{"min-elements", la.MinElements},
+			{"max-elements", la.MaxElements},
+			{"ordered-by", la.OrderedBy},
+		} {
+			if a.v != nil {
+				fmt.Fprintf(w, " %s=%s", a.name, a.v.Name)
+			}
+		}
+	}
+	if e.Default != "" || len(e.Defaults) > 0 {
+		fmt.Fprintf(w, " default=%q", append([]string{e.Default}, e.Defaults...))
+	}
+	if e.Type != nil {
+		fmt.Fprint(w, " type=")
+		writeCanonicalType(w, e.Type)
+	}
+	fmt.Fprintln(w)
+	if e.RPC != nil {
+		for _, p := range []*yang.Entry{e.RPC.Input, e.RPC.Output} {
+			if p != nil {
+				writeCanonical(w, p)
+			}
+		}
+	}
+	for _, se := range sortedDir(e) {
+		writeCanonical(w, se)
+	}
+}
+
+// writeCanonicalType writes the canonical form of the type t to w.
+func writeCanonicalType(w io.Writer, t *yang.YangType) {
+	fmt.Fprintf(w, "%s(%s)", t.Name, t.Kind)
+	for _, et := range []*yang.EnumType{t.Enum, t.Bit} {
+		if et == nil {
+			continue
+		}
+		for _, n := range et.Names() {
+			fmt.Fprintf(w, " %s=%d", n, et.Value(n))
+		}
+	}
+	if t.IdentityBase != nil {
+		fmt.Fprintf(w, " base=%s", t.IdentityBase.PrefixedName())
+	}
+	if t.Units != "" {
+		fmt.Fprintf(w, " units=%q", t.Units)
+	}
+	if t.Default != "" {
+		fmt.Fprintf(w, " default=%q", t.Default)
+	}
+	if t.FractionDigits != 0 {
+		fmt.Fprintf(w, " fraction-digits=%d", t.FractionDigits)
+	}
+	if len(t.Length) > 0 {
+		fmt.Fprintf(w, " length=%s", t.Length)
+	}
+	if len(t.Range) > 0 {
+		fmt.Fprintf(w, " range=%s", t.Range)
+	}
+	if t.Path != "" {
+		fmt.Fprintf(w, " path=%q require-instance=%v", t.Path, !t.OptionalInstance)
+	}
+	for _, p := range t.Pattern {
+		fmt.Fprintf(w, " pattern=%q", p)
+	}
+	for _, ut := range t.Type {
+		fmt.Fprint(w, " {")
+		writeCanonicalType(w, ut)
+		fmt.Fprint(w, "}")
+	}
+}
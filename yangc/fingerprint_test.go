@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+const fingerprintModule = `
+module fp {
+  namespace "urn:fp";
+  prefix "f";
+
+  container system {
+    description "%s";
+    leaf mtu { type %s; }
+  }
+}
+`
+
+func TestFingerprint(t *testing.T) {
+	compile := func(description, typ string) string {
+		src := fmt.Sprintf(fingerprintModule, description, typ)
+		return fingerprint(compileString(t, "fp.yang", src)[0])
+	}
+	base := compile("System settings.", "uint32")
+	if again := compile("System settings.", "uint32"); again != base {
+		t.Errorf("fingerprint not stable: %s and %s", base, again)
+	}
+	if got := compile("Settings of the\n      system.", "uint32"); got != base {
+		t.Errorf("description change changed the fingerprint: %s, want %s", got, base)
+	}
+	if got := compile("System settings.", "uint16"); got == base {
+		t.Errorf("type change did not change the fingerprint %s", base)
+	}
+}
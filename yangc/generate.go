@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+var (
+	formats     []string
+	outputDir   string
+	splitSource bool
+)
+
+// A format is an output format the generate command can produce.
+type format struct {
+	ext string // suffix of the generated file name
+	gen func(io.Writer, []*yang.Entry)
+}
+
+// generators maps the names accepted by --format to their emitters.
+var generators = map[string]format{
+	"header":     {".h", doHeader},
+	"type":       {"_type.h", doType},
+	"table":      {"_table.h", doTable},
+	"proto":      {".proto", doProto},
+	"tree":       {".tree", doTree},
+	"typescript": {".ts", doTypeScript},
+	"capnp":      {".capnp", doCapnp},
+	"graph":      {".dot", doGraph},
+	"json":       {".json", doJSON},
+}
+
+func init() {
+	var generateCmd = &cobra.Command{
+		Use:   "generate",
+		Short: "generate several formats from a single compile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkFormats(formats); err != nil {
+				return err
+			}
+			err := runGenerate(outputDir, yangFileName, formats)
+			if err == errCompile {
+				// The errors are already reported.
+				cmd.SilenceUsage, cmd.SilenceErrors = true, true
+			}
+			return err
+		},
+	}
+	generateCmd.Flags().StringSliceVar(&formats, "format", []string{"header"}, "comma separated list of formats to generate")
+	generateCmd.Flags().StringVar(&outputDir, "output-dir", ".", "directory the generated files are written to")
+	generateCmd.Flags().BoolVar(&splitSource, "split-source", false, "write the definitions of C formats to a .c file, leaving declarations in the .h")
+	mainCmd.AddCommand(generateCmd)
+}
+
+// errCompile is returned by runGenerate when fileName does not compile.
+var errCompile = errors.New("compile failed")
+
+// runGenerate compiles fileName and writes it in each of the named formats
+// to dir.  Compile errors are reported as diagnostics and errCompile is
+// returned, so main exits with their summary.
+func runGenerate(dir, fileName string, names []string) error {
+	entries, errs := compile(fileName)
+	if len(errs) > 0 {
+		reportErrors(errs)
+		return errCompile
+	}
+	return doGenerate(dir, fileName, names, entries)
+}
+
+// checkFormats returns an error if any of names is not a known format.
+func checkFormats(names []string) error {
+	for _, name := range names {
+		if _, ok := generators[name]; !ok {
+			return fmt.Errorf("unknown format %q", name)
+		}
+	}
+	return nil
+}
+
+// doGenerate writes entries in each of the named formats to dir.  The
+// files are named after fileName, or stdin if it is empty, with the
+// extension of the format.  With --split-source each C header format is
+// paired with a .c file holding its definitions.
+func doGenerate(dir, fileName string, names []string, entries []*yang.Entry) error {
+	base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	if fileName == "" {
+		base = "stdin"
+	}
+	for _, name := range names {
+		f := generators[name]
+		gen := func(w io.Writer) { f.gen(w, entries) }
+		if !splitSource || !strings.HasSuffix(f.ext, ".h") {
+			if err := writeFile(filepath.Join(dir, base+f.ext), gen); err != nil {
+				return err
+			}
+			continue
+		}
+		var defs bytes.Buffer
+		definitions = &defs
+		err := writeFile(filepath.Join(dir, base+f.ext), gen)
+		definitions = nil
+		if err != nil {
+			return err
+		}
+		src := base + strings.TrimSuffix(f.ext, ".h") + ".c"
+		err = writeFile(filepath.Join(dir, src), func(w io.Writer) {
+			fmt.Fprintf(w, "// Automatically generated by yangc\n")
+			fmt.Fprintf(w, "#include %q\n\n", base+f.ext)
+			w.Write(defs.Bytes())
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile creates the file out and writes its contents with gen.
+func writeFile(out string, gen func(io.Writer)) error {
+	fd, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(fd)
+	gen(bw)
+	err = bw.Flush()
+	if cerr := fd.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", out, err)
+	}
+	return nil
+}
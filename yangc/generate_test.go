@@ -0,0 +1,128 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	entries := compileString(t, "gen.yang", `
+module gen {
+  namespace "urn:gen";
+  prefix "gen";
+
+  container system {
+    leaf host-name { type string; }
+  }
+}
+`)
+	dir, err := ioutil.TempDir("", "yangc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := doGenerate(dir, "models/gen.yang", []string{"header", "json"}, entries); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"gen.h":    "host_name",
+		"gen.json": `"name": "host-name"`,
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s not generated: %v", name, err)
+			continue
+		}
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%s does not contain %q:\n%s", name, want, b)
+		}
+	}
+}
+
+func TestCheckFormats(t *testing.T) {
+	if err := checkFormats([]string{"header", "table", "json"}); err != nil {
+		t.Errorf("got error %v", err)
+	}
+	if err := checkFormats([]string{"header", "cobol"}); err == nil {
+		t.Error("unknown format did not fail")
+	}
+}
+
+func TestGenerateSplitSource(t *testing.T) {
+	defer func(b bool) { splitSource = b }(splitSource)
+	defer func(b bool) { withEnumNames = b }(withEnumNames)
+	splitSource = true
+	withEnumNames = true
+
+	entries := compileString(t, "paint.yang", `
+module paint {
+  namespace "urn:paint";
+  prefix "p";
+
+  container brush {
+    leaf color { type enumeration { enum red; enum green; } }
+  }
+}
+`)
+	dir, err := ioutil.TempDir("", "yangc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := doGenerate(dir, "paint.yang", []string{"header", "proto"}, entries); err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("%s not generated: %v", name, err)
+		}
+		return string(b)
+	}
+	h, c := read("paint.h"), read("paint.c")
+	definition := `const char *ColorName[] = { "red", "green" };`
+	if !strings.Contains(h, "extern const char *ColorName[];") || strings.Contains(h, definition) {
+		t.Errorf("paint.h should only declare ColorName:\n%s", h)
+	}
+	if !strings.Contains(c, `#include "paint.h"`) || !strings.Contains(c, definition) {
+		t.Errorf("paint.c should define ColorName:\n%s", c)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 3 {
+		t.Errorf("got %d files, want paint.h, paint.c and paint.proto", len(files))
+	}
+}
+
+func TestGenerateCompileErrors(t *testing.T) {
+	defer func(c map[string]int, w io.Writer, f func(int)) {
+		diagnosticCounts, diagOut, exit = c, w, f
+	}(diagnosticCounts, diagOut, exit)
+	diagnosticCounts = map[string]int{}
+	diagOut = ioutil.Discard
+	exit = func(code int) { t.Fatalf("exited with status %d", code) }
+
+	dir, err := ioutil.TempDir("", "yangc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "broken.yang")
+	if err := ioutil.WriteFile(src, []byte(`module broken { namespace "urn:broken"; prefix "b"; leaf l { type no-such-type; } }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runGenerate(dir, src, []string{"header"}); err != errCompile {
+		t.Errorf("got error %v, want %v", err, errCompile)
+	}
+	if diagnosticCounts["error"] == 0 {
+		t.Error("compile errors not reported")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.h")); err == nil {
+		t.Error("broken.h generated")
+	}
+}
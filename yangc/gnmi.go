@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+// gnmiJSON writes gNMI paths as JSON encoded gnmi.Path messages.
+var gnmiJSON bool
+
+func init() {
+	var gnmiCmd = &cobra.Command{
+		Use:   "gnmi-paths",
+		Short: "print the gNMI path of each leaf",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doGNMIPaths(os.Stdout, entries)
+		},
+	}
+	gnmiCmd.Flags().BoolVar(&gnmiJSON, "json", false, "write each path as a JSON encoded gnmi.Path")
+	mainCmd.AddCommand(gnmiCmd)
+}
+
+// A gnmiPathElem is the JSON encoding of a gnmi.PathElem.  Keys of lists
+// are wildcards.
+type gnmiPathElem struct {
+	Name string            `json:"name"`
+	Key  map[string]string `json:"key,omitempty"`
+	keys []string          // names in Key in the order of the list keys
+}
+
+// A gnmiPath is the JSON encoding of a gnmi.Path.
+type gnmiPath struct {
+	Elem []gnmiPathElem `json:"elem"`
+}
+
+// newGNMIPath returns the gNMI path of the data node at the end of path.
+// Nodes augmented in from other modules are qualified with the name of their
+// module.
+func newGNMIPath(path []*yang.Entry) gnmiPath {
+	var gp gnmiPath
+	for i, e := range path {
+		pe := gnmiPathElem{Name: e.PathName()}
+		if i == 0 {
+			pe.Name = e.Name
+		}
+		for _, k := range listKeys(e) {
+			if pe.Key == nil {
+				pe.Key = map[string]string{}
+			}
+			pe.Key[k] = "*"
+			pe.keys = append(pe.keys, k)
+		}
+		gp.Elem = append(gp.Elem, pe)
+	}
+	return gp
+}
+
+// String returns gp in the gNMI path string format, as in
+// /interfaces/interface[name=*]/mtu.
+func (gp gnmiPath) String() string {
+	var s string
+	for _, pe := range gp.Elem {
+		s += "/" + pe.Name
+		for _, k := range pe.keys {
+			s += fmt.Sprintf("[%s=%s]", k, pe.Key[k])
+		}
+	}
+	return s
+}
+
+// doGNMIPaths writes the gNMI path of every leaf and leaf-list in entries to
+// w, one per line.
+func doGNMIPaths(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		for _, se := range children(e) {
+			walkDataNodes(se, nil, func(path []*yang.Entry) {
+				if isDirectory(path[len(path)-1]) {
+					return
+				}
+				gp := newGNMIPath(path)
+				if !gnmiJSON {
+					fmt.Fprintln(w, gp)
+					return
+				}
+				b, err := json.Marshal(gp)
+				if err != nil {
+					reportError(err)
+					return
+				}
+				fmt.Fprintf(w, "%s\n", b)
+			})
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGNMIPaths(t *testing.T) {
+	defer func(b bool) { gnmiJSON = b }(gnmiJSON)
+
+	entries := compileString(t, "gnmi.yang", `
+module gnmi {
+  namespace "urn:gnmi";
+  prefix "g";
+
+  container interfaces {
+    list interface {
+      key name;
+      leaf name { type string; }
+      list subinterface {
+        key "index vlan";
+        leaf index { type uint32; }
+        leaf vlan { type uint16; }
+        leaf-list address { type string; }
+      }
+    }
+  }
+}
+`)
+	for _, tt := range []struct {
+		json bool
+		want string
+	}{
+		{false, `/interfaces/interface[name=*]/name
+/interfaces/interface[name=*]/subinterface[index=*][vlan=*]/index
+/interfaces/interface[name=*]/subinterface[index=*][vlan=*]/vlan
+/interfaces/interface[name=*]/subinterface[index=*][vlan=*]/address
+`},
+		{true, `{"elem":[{"name":"interfaces"},{"name":"interface","key":{"name":"*"}},{"name":"name"}]}
+{"elem":[{"name":"interfaces"},{"name":"interface","key":{"name":"*"}},{"name":"subinterface","key":{"index":"*","vlan":"*"}},{"name":"index"}]}
+{"elem":[{"name":"interfaces"},{"name":"interface","key":{"name":"*"}},{"name":"subinterface","key":{"index":"*","vlan":"*"}},{"name":"vlan"}]}
+{"elem":[{"name":"interfaces"},{"name":"interface","key":{"name":"*"}},{"name":"subinterface","key":{"index":"*","vlan":"*"}},{"name":"address"}]}
+`},
+	} {
+		gnmiJSON = tt.json
+		var buf bytes.Buffer
+		doGNMIPaths(&buf, entries)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("json=%v: got:\n%s\nwant:\n%s", tt.json, got, tt.want)
+		}
+	}
+}
+
+func TestGNMIAugmentPaths(t *testing.T) {
+	defer func(b bool) { gnmiJSON = b }(gnmiJSON)
+	gnmiJSON = false
+
+	entries := compileStrings(t, map[string]string{
+		"base": `
+module base {
+  namespace "urn:base";
+  prefix "b";
+
+  container system {
+    container clock { leaf timezone { type string; } }
+  }
+}
+`,
+		"extra": `
+module extra {
+  namespace "urn:extra";
+  prefix "x";
+  import base { prefix b; }
+
+  augment "/b:system/b:clock" {
+    container offset { leaf minutes { type int16; } }
+  }
+}
+`,
+	})
+	var buf bytes.Buffer
+	doGNMIPaths(&buf, entries)
+	for _, want := range []string{
+		"/system/clock/timezone\n",
+		"/system/clock/extra:offset/minutes\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// goldenGenerators are the generators covered by the golden tests.  The
+// output of generator name for testdata/golden/x.yang is compared to
+// testdata/golden/x.name.golden.
+var goldenGenerators = []struct {
+	name string
+	gen  func(io.Writer, []*yang.Entry)
+}{
+	{"header", doHeader},
+	{"table", doTable},
+	{"type", doType},
+	{"proto", doProto},
+}
+
+func TestGolden(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	files, err := filepath.Glob(filepath.Join("testdata", "golden", "*.yang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no golden test models found")
+	}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries := compileString(t, filepath.Base(file), string(src))
+		for _, g := range goldenGenerators {
+			var buf bytes.Buffer
+			g.gen(&buf, entries)
+			golden := strings.TrimSuffix(file, ".yang") + "." + g.name + ".golden"
+			if *update {
+				if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Errorf("%v (run go test -update to create it)", err)
+				continue
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("%s: output differs from %s:\n%s", file, golden, diffLines(string(want), got))
+			}
+		}
+	}
+}
+
+// diffLines returns the lines that differ between want and got, each marked
+// with the line number and - for want or + for got.
+func diffLines(want, got string) string {
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < len(wl) || i < len(gl); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g {
+			fmt.Fprintf(&b, "%d-%s\n%d+%s\n", i+1, w, i+1, g)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var graphCmd = &cobra.Command{
+		Use:   "graph",
+		Short: "print the module import/include graph in DOT format",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doGraph(os.Stdout, entries)
+		},
+	}
+	mainCmd.AddCommand(graphCmd)
+}
+
+// doGraph writes the import and include dependencies of all modules loaded
+// along with entries to w as a Graphviz digraph.  Imports are drawn as solid
+// edges and includes as dashed edges.
+func doGraph(w io.Writer, entries []*yang.Entry) {
+	seen := map[*yang.Module]bool{}
+	var mods []*yang.Module
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		ms := e.Modules()
+		for _, se := range e.Dir {
+			if ms != nil {
+				break
+			}
+			// The tree built by --merge is not part of a
+			// module set, the nodes in it are.
+			ms = se.Modules()
+		}
+		if ms == nil {
+			continue
+		}
+		for _, m := range ms.Modules {
+			if !seen[m] {
+				seen[m] = true
+				mods = append(mods, m)
+			}
+		}
+		for _, m := range ms.SubModules {
+			if !seen[m] {
+				seen[m] = true
+				mods = append(mods, m)
+			}
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name < mods[j].Name })
+
+	fmt.Fprintln(w, "digraph yang {") // matching brace }
+	for _, m := range mods {
+		if m.Kind() == "submodule" {
+			fmt.Fprintf(w, "%s%q [shape=box];\n", indentString, m.Name)
+		} else {
+			fmt.Fprintf(w, "%s%q;\n", indentString, m.Name)
+		}
+	}
+	for _, m := range mods {
+		for _, i := range m.Import {
+			fmt.Fprintf(w, "%s%q -> %q;\n", indentString, m.Name, i.Name)
+		}
+		for _, i := range m.Include {
+			fmt.Fprintf(w, "%s%q -> %q [style=dashed];\n", indentString, m.Name, i.Name)
+		}
+	}
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	entries := compileStrings(t, map[string]string{
+		"app": `module app {
+  namespace "urn:app"; prefix "app";
+  import types { prefix t; }
+  import base { prefix b; }
+}`,
+		"types": `module types { namespace "urn:types"; prefix "t"; }`,
+		"base":  `module base { namespace "urn:base"; prefix "b"; }`,
+	})
+
+	var buf bytes.Buffer
+	doGraph(&buf, entries)
+	got := buf.String()
+	for _, want := range []string{
+		"digraph yang {\n",
+		`  "app";`,
+		`  "base";`,
+		`  "types";`,
+		`  "app" -> "types";`,
+		`  "app" -> "base";`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in output:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "->"); n != 2 {
+		t.Errorf("got %d edges, want 2:\n%s", n, got)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// withIdentityTree emits the identities of each module as an enum along
+// with a comment showing their base/derived hierarchy.
+var withIdentityTree bool
+
+// localBase returns the name of the base of identity i if the base is
+// defined in module m, otherwise it returns "".
+func localBase(m *yang.Module, i *yang.Identity) string {
+	if i.Base == nil {
+		return ""
+	}
+	base := i.Base.Name
+	if n := strings.Index(base, ":"); n >= 0 {
+		if base[:n] != m.GetPrefix() {
+			return ""
+		}
+		base = base[n+1:]
+	}
+	for _, id := range m.Identities() {
+		if id.Name == base {
+			return base
+		}
+	}
+	return ""
+}
+
+// writeIdentityTree writes the identities defined in the module e to w as
+// an enum, preceded by a comment showing which identity derives from which.
+// Identities are listed depth first, each after its base.  Nothing is
+// written if the module defines no identities.
+func (pf *protofile) writeIdentityTree(w io.Writer, e *yang.Entry) {
+	m, ok := e.Node.(*yang.Module)
+	if !ok || len(m.Identities()) == 0 {
+		return
+	}
+	var roots []*yang.Identity
+	derived := map[string][]*yang.Identity{}
+	for _, i := range m.Identities() {
+		if base := localBase(m, i); base != "" {
+			derived[base] = append(derived[base], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var ordered []*yang.Identity
+	fmt.Fprintf(w, "\n// identity hierarchy of module %s\n", m.Name)
+	var walk func(i *yang.Identity, depth int)
+	walk = func(i *yang.Identity, depth int) {
+		ordered = append(ordered, i)
+		fmt.Fprintf(w, "//%s%s", strings.Repeat(indentString, depth+1), i.Name)
+		if i.Base != nil {
+			fmt.Fprintf(w, " (base %s)", i.Base.Name)
+		}
+		fmt.Fprintln(w)
+		for _, d := range derived[i.Name] {
+			walk(d, depth+1)
+		}
+	}
+	for _, i := range roots {
+		walk(i, 0)
+	}
+
+	kind := yang.CamelCase(m.Name) + "Identity"
+	members := make([]cEnumMember, len(ordered))
+	seen := map[string]string{}
+	for n, i := range ordered {
+		members[n] = cEnumMember{name: pf.enumMemberName(i, kind, seen, i.Name), value: int64(n)}
+		if i.Base != nil {
+			members[n].comment = "base " + i.Base.Name
+		}
+	}
+	writeCEnum(w, "", kind, "", members)
+}
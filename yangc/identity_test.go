@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIdentityTree(t *testing.T) {
+	defer func(b bool) { withIdentityTree = b }(withIdentityTree)
+	withIdentityTree = true
+
+	entries := compileString(t, "crypto.yang", `
+module crypto {
+  namespace "urn:crypto";
+  prefix "c";
+
+  identity crypto-alg;
+  identity des { base crypto-alg; }
+  identity aes { base c:crypto-alg; }
+
+  leaf alg { type identityref { base crypto-alg; } }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	want := `
+// identity hierarchy of module crypto
+//  crypto-alg
+//    des (base crypto-alg)
+//    aes (base c:crypto-alg)
+enum CryptoIdentity {
+  CryptoIdentity_CRYPTO_ALG = 0;
+  CryptoIdentity_DES = 1; // base crypto-alg
+  CryptoIdentity_AES = 2; // base c:crypto-alg
+};
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("missing identity tree in output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	withIdentityTree = false
+	buf.Reset()
+	doHeader(&buf, entries)
+	if strings.Contains(buf.String(), "CryptoIdentity") {
+		t.Errorf("identity tree emitted without --with-identity-tree:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var importsCmd = &cobra.Command{
+		Use:   "imports",
+		Short: "list the imports declared by each module, with their prefixes",
+		Run: func(cmd *cobra.Command, args []string) {
+			ms, errs := readModules(yangFileName)
+			exitIfError(errs)
+			doImports(os.Stdout, ms)
+		},
+	}
+	mainCmd.AddCommand(importsCmd)
+}
+
+// doImports writes the modules read into ms to w, each followed by the
+// prefix, module name and revision-date, if any, of its imports.  The
+// imports are listed as declared, without being resolved, so it works when
+// the imported modules cannot be found.
+func doImports(w io.Writer, ms *yang.Modules) {
+	for _, m := range parsedModules(ms) {
+		fmt.Fprintf(w, "%s\n", m.Name)
+		for _, i := range m.Import {
+			fmt.Fprintf(w, "%s%s %s", indentString, i.Prefix.Name, i.Name)
+			if i.RevisionDate != nil {
+				fmt.Fprintf(w, " %s", i.RevisionDate.Name)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// parsedModules returns the modules read into ms, sorted by name, each once
+// even when it is known by its revision as well.
+func parsedModules(ms *yang.Modules) []*yang.Module {
+	seen := map[*yang.Module]bool{}
+	var mods []*yang.Module
+	for _, m := range ms.Modules {
+		if !seen[m] {
+			seen[m] = true
+			mods = append(mods, m)
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name < mods[j].Name })
+	return mods
+}
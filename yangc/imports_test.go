@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestImports(t *testing.T) {
+	ms := yang.NewModules()
+	// Neither imported module is available, the imports are listed all the
+	// same.
+	if err := ms.Parse(`
+module router {
+  namespace "urn:router";
+  prefix "r";
+
+  import ietf-interfaces { prefix if; revision-date 2018-02-20; }
+  import ietf-inet-types { prefix inet; }
+
+  leaf name { type string; }
+}
+`, "router.yang"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	doImports(&buf, ms)
+	want := "router\n" +
+		indentString + "if ietf-interfaces 2018-02-20\n" +
+		indentString + "inet ietf-inet-types\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// inlineImportedTypes emits the imported typedefs a module uses along with
+// the module so the output is self-contained.
+var inlineImportedTypes bool
+
+// importedTypedefs returns the typedefs from modules other than e's that are
+// used, directly or through other typedefs, by e and its descendants.  The
+// typedefs are sorted by module and name.
+func importedTypedefs(e *yang.Entry) []*yang.Typedef {
+	mod := yang.ModuleName(e.Node)
+	seen := map[*yang.Typedef]bool{}
+	var tds []*yang.Typedef
+
+	var addType func(t *yang.YangType)
+	addType = func(t *yang.YangType) {
+		if t == nil {
+			return
+		}
+		for _, ut := range t.Type {
+			addType(ut)
+		}
+		if t.Base == nil {
+			return
+		}
+		td, ok := t.Base.Parent.(*yang.Typedef)
+		if !ok || td.Parent == nil || seen[td] {
+			return // built in type or already seen
+		}
+		seen[td] = true
+		if yang.ModuleName(td) != mod {
+			tds = append(tds, td)
+		}
+		addType(td.Type.YangType)
+	}
+	var addEntry func(e *yang.Entry)
+	addEntry = func(e *yang.Entry) {
+		if e == nil {
+			return
+		}
+		addType(e.Type)
+		if td, ok := e.Node.(*yang.Typedef); ok && e.Kind == yang.TypedefEntry {
+			addType(td.Type.YangType)
+		}
+		if e.RPC != nil {
+			addEntry(e.RPC.Input)
+			addEntry(e.RPC.Output)
+		}
+		for _, se := range e.Dir {
+			addEntry(se)
+		}
+	}
+	addEntry(e)
+
+	sort.Slice(tds, func(i, j int) bool {
+		mi, mj := yang.ModuleName(tds[i]), yang.ModuleName(tds[j])
+		if mi != mj {
+			return mi < mj
+		}
+		return tds[i].Name < tds[j].Name
+	})
+	return tds
+}
+
+// writeImportedTypedefs writes the imported typedefs used by the module e
+// to w.  Nothing is written with --base-type-only.
+func (pf *protofile) writeImportedTypedefs(w io.Writer, e *yang.Entry) {
+	if baseTypeOnly {
+		return
+	}
+	mod := ""
+	for _, td := range importedTypedefs(e) {
+		if m := yang.ModuleName(td); m != mod {
+			mod = m
+			fmt.Fprintf(w, "\n// imported from module %q\n", mod)
+		}
+		pf.WriteHeaders(w, yang.ToEntry(td), true, false)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestInlineImportedTypes(t *testing.T) {
+	defer func(b bool) { inlineImportedTypes = b }(inlineImportedTypes)
+
+	entries := compileStrings(t, map[string]string{
+		"types": `
+module types {
+  namespace "urn:types";
+  prefix "t";
+
+  typedef percent {
+    type uint8 { range "0..100"; }
+  }
+  typedef load {
+    type percent;
+  }
+  typedef unused { type string; }
+}
+`,
+		"main": `
+module main {
+  namespace "urn:main";
+  prefix "m";
+  import types { prefix t; }
+
+  container c {
+    leaf cpu { type t:load; }
+  }
+}
+`,
+	})
+	var mod *yang.Entry
+	for _, e := range entries {
+		if e.Name == "main" {
+			mod = e
+		}
+	}
+	if mod == nil {
+		t.Fatal("module main not compiled")
+	}
+
+	var names []string
+	for _, td := range importedTypedefs(mod) {
+		names = append(names, td.Name)
+	}
+	if got, want := strings.Join(names, " "), "load percent"; got != want {
+		t.Errorf("imported typedefs %q, want %q", got, want)
+	}
+
+	for _, inline := range []bool{false, true} {
+		inlineImportedTypes = inline
+		var buf bytes.Buffer
+		doHeader(&buf, []*yang.Entry{mod})
+		out := buf.String()
+		for _, want := range []string{"// imported from module \"types\"\n", "typedef Load {", "typedef Percent {"} {
+			if strings.Contains(out, want) != inline {
+				t.Errorf("inline=%v: %q in output is %v:\n%s", inline, want, !inline, out)
+			}
+		}
+		if strings.Contains(out, "typedef Unused") {
+			t.Errorf("inline=%v: unused typedef emitted:\n%s", inline, out)
+		}
+	}
+}
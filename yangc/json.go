@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// A jsonEntry is the JSON encoding of a schema node and its children.
+type jsonEntry struct {
+	Name        string       `json:"name"`
+	Path        string       `json:"path,omitempty"`
+	Kind        string       `json:"kind"`
+	Type        string       `json:"type,omitempty"`
+	Leafref     string       `json:"leafref,omitempty"`
+	List        bool         `json:"list,omitempty"`
+	Key         string       `json:"key,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Children    []*jsonEntry `json:"children,omitempty"`
+}
+
+// newJSONEntry returns the jsonEntry for e and its children.
+func newJSONEntry(e *yang.Entry) *jsonEntry {
+	je := &jsonEntry{
+		Name:        e.Name,
+		Path:        e.FullPath(),
+		Kind:        e.Kind.String(),
+		List:        e.ListAttr != nil,
+		Key:         e.Key,
+		Description: e.Description,
+	}
+	if e.Type != nil {
+		je.Type = e.Type.Name
+		if e.Type.Kind == yang.Yleafref && e.Type.Path != "" {
+			je.Leafref = leafrefPath(e, e.Type)
+		}
+	}
+	for _, c := range sortedDir(e) {
+		je.Children = append(je.Children, newJSONEntry(c))
+	}
+	return je
+}
+
+// doJSON writes each module in entries to w as an indented JSON array.
+func doJSON(w io.Writer, entries []*yang.Entry) {
+	jes := []*jsonEntry{}
+	for _, e := range entries {
+		if e != nil {
+			jes = append(jes, newJSONEntry(e))
+		}
+	}
+	b, err := json.MarshalIndent(jes, "", indentString)
+	if err != nil {
+		reportError(err)
+		return
+	}
+	fmt.Fprintf(w, "%s\n", b)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// relativePaths writes leafref paths as declared rather than normalized to
+// the absolute path of the leaf they refer to.
+var relativePaths bool
+
+func init() {
+	mainCmd.PersistentFlags().BoolVar(&relativePaths, "relative-paths", false, "write leafref paths as declared, possibly relative, instead of as absolute paths")
+}
+
+// leafrefPath returns the path of the leafref type t of e, either e's own
+// type or a member of its union.  Unless --relative-paths is set, the path is
+// normalized to the absolute path of the referenced node, without
+// predicates.  A path that does not resolve is returned as declared.
+func leafrefPath(e *yang.Entry, t *yang.YangType) string {
+	if relativePaths {
+		return t.Path
+	}
+	if target := e.Find(stripPredicates(t.Path)); target != nil {
+		return target.FullPath()
+	}
+	return t.Path
+}
+
+// leafrefPaths returns, as written by leafrefPath, the path of t if it is a
+// leafref, or the paths of its leafref members if it is a union.
+func leafrefPaths(e *yang.Entry, t *yang.YangType) []string {
+	if t.Kind == yang.Yleafref && t.Path != "" {
+		return []string{leafrefPath(e, t)}
+	}
+	var paths []string
+	for _, ut := range t.Type {
+		paths = append(paths, leafrefPaths(e, ut)...)
+	}
+	return paths
+}
+
+// resolveLeafref follows the chain of leafrefs starting at e and returns the
+// entry that is ultimately referenced.  nil is returned if e is not a
+// leafref, or if the path cannot be resolved.
+func resolveLeafref(e *yang.Entry) *yang.Entry {
+	seen := map[*yang.Entry]bool{}
+	for e != nil && e.Type != nil && e.Type.Kind == yang.Yleafref {
+		if seen[e] {
+			return nil // circular leafref
+		}
+		seen[e] = true
+		e = e.Find(stripPredicates(e.Type.Path))
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	return e
+}
+
+// stripPredicates removes all [...] predicates from the leafref path p.
+func stripPredicates(p string) string {
+	var b strings.Builder
+	depth := 0
+	for _, c := range p {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(c)
+		}
+	}
+	return strings.Replace(b.String(), " ", "", -1)
+}
+
+// isKey returns true if e is one of the key leaves of its parent list.
+func isKey(e *yang.Entry) bool {
+	if e.Parent == nil || e.Parent.ListAttr == nil {
+		return false
+	}
+	for _, k := range strings.Fields(e.Parent.Key) {
+		if k == e.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldType returns the type used when generating the field for e.  List
+// keys that are leafrefs are resolved to the type of the leaf they
+// reference, all other entries use their own type.
+func fieldType(e *yang.Entry) *yang.YangType {
+	if e.Type == nil || e.Type.Kind != yang.Yleafref || !isKey(e) {
+		return e.Type
+	}
+	if re := resolveLeafref(e); re != nil && re.Type != nil {
+		return re.Type
+	}
+	return e.Type
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+const leafrefKeyModule = `
+module leafref-key {
+  namespace "urn:leafref-key";
+  prefix "lk";
+
+  list interface {
+    key id;
+    leaf id { type uint32; }
+  }
+  list counters {
+    key if-id;
+    leaf if-id {
+      type leafref { path "/lk:interface/lk:id"; }
+    }
+    leaf alias {
+      type leafref { path "../if-id"; }
+    }
+  }
+}
+`
+
+func TestResolveLeafref(t *testing.T) {
+	e := compileString(t, "leafref-key.yang", leafrefKeyModule)[0]
+	counters := e.Dir["counters"]
+	for _, name := range []string{"if-id", "alias"} {
+		re := resolveLeafref(counters.Dir[name])
+		if re == nil {
+			t.Errorf("%s: leafref not resolved", name)
+			continue
+		}
+		if got, want := re.Path(), "/leafref-key/interface/id"; got != want {
+			t.Errorf("%s: resolved to %s, want %s", name, got, want)
+		}
+	}
+	if re := resolveLeafref(e.Dir["interface"].Dir["id"]); re != nil {
+		t.Errorf("resolveLeafref of a uint32 returned %s, want nil", re.Path())
+	}
+}
+
+func TestKeyLeafrefType(t *testing.T) {
+	e := compileString(t, "leafref-key.yang", leafrefKeyModule)[0]
+	counters := e.Dir["counters"]
+	if got := fieldType(counters.Dir["if-id"]).Kind; got != yang.Yuint32 {
+		t.Errorf("key if-id has type %s, want uint32", got)
+	}
+	// Only keys are resolved.
+	if got := fieldType(counters.Dir["alias"]).Kind; got != yang.Yleafref {
+		t.Errorf("non-key alias has type %s, want leafref", got)
+	}
+
+	var buf bytes.Buffer
+	doProto(&buf, []*yang.Entry{e})
+	if want := "  uint32 if_id = "; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestRelativePaths(t *testing.T) {
+	defer func(b bool) { relativePaths = b }(relativePaths)
+
+	e := compileString(t, "leafref-key.yang", leafrefKeyModule)[0]
+	for _, tt := range []struct {
+		relative bool
+		want     []string
+	}{
+		{false, []string{`"leafref": "/leafref-key:interface/id"`, `"leafref": "/leafref-key:counters/if-id"`}},
+		{true, []string{`"leafref": "/lk:interface/lk:id"`, `"leafref": "../if-id"`}},
+	} {
+		relativePaths = tt.relative
+		var buf bytes.Buffer
+		doJSON(&buf, []*yang.Entry{e})
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("relative %v: missing %q in output:\n%s", tt.relative, want, buf.String())
+			}
+		}
+	}
+}
+
+func TestLeafrefComment(t *testing.T) {
+	defer func(b bool) { relativePaths = b }(relativePaths)
+
+	e := compileString(t, "leafref-union.yang", `
+module leafref-union {
+  namespace "urn:leafref-union";
+  prefix "lu";
+
+  container system {
+    leaf name { type string; }
+    leaf host { type leafref { path "../name"; } }
+    leaf ref {
+      type union {
+        type string;
+        type leafref { path "/lu:system/lu:name"; }
+      }
+    }
+  }
+}
+`)[0]
+	for _, tt := range []struct {
+		relative bool
+		host     string
+		ref      string
+	}{
+		{false, "/leafref-union:system/name", "/leafref-union:system/name"},
+		{true, "../name", "/lu:system/lu:name"},
+	} {
+		relativePaths = tt.relative
+		for name, gen := range map[string]func(io.Writer, []*yang.Entry){
+			"proto":  doProto,
+			"header": doHeader,
+		} {
+			var buf bytes.Buffer
+			gen(&buf, []*yang.Entry{e})
+			for field, path := range map[string]string{"host": tt.host, "ref": tt.ref} {
+				re := regexp.MustCompile(" " + field + ` = \d+; // leafref=` + regexp.QuoteMeta(path) + "\n")
+				if !re.MatchString(buf.String()) {
+					t.Errorf("%s, relative %v: %s has no leafref=%s in output:\n%s", name, tt.relative, field, path, buf.String())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var listTypesCmd = &cobra.Command{
+		Use:   "list-types",
+		Short: "print the base type kinds used by leaves, with counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doListTypes(os.Stdout, entries)
+		},
+	}
+	mainCmd.AddCommand(listTypesCmd)
+}
+
+// KindCounts counts the leaves of each base type kind.
+type KindCounts map[yang.TypeKind]int
+
+// AddEntry counts all leaves in e and its decendents in c.
+func (c KindCounts) AddEntry(e *yang.Entry) {
+	if e == nil {
+		return
+	}
+	if e.Type != nil {
+		c[e.Type.Kind]++
+	}
+	if e.RPC != nil {
+		c.AddEntry(e.RPC.Input)
+		c.AddEntry(e.RPC.Output)
+	}
+	for _, d := range e.Dir {
+		c.AddEntry(d)
+	}
+}
+
+// doListTypes writes each type kind used by a leaf in entries, and the number
+// of leaves using it, to w sorted by kind name.
+func doListTypes(w io.Writer, entries []*yang.Entry) {
+	counts := KindCounts{}
+	for _, e := range entries {
+		counts.AddEntry(e)
+	}
+	var kinds []yang.TypeKind
+	for k := range counts {
+		kinds = append(kinds, k)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+	for _, k := range kinds {
+		fmt.Fprintf(w, "%-20s %d\n", k, counts[k])
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestListTypes(t *testing.T) {
+	e := compileString(t, "kinds.yang", `
+module kinds {
+  namespace "urn:kinds";
+  prefix "k";
+
+  list port {
+    key id;
+    leaf id { type uint32; }
+    leaf speed { type uint32; }
+    leaf mtu { type uint32; }
+    leaf name { type string; }
+    leaf descr { type string; }
+    leaf state {
+      type enumeration {
+        enum up;
+        enum down;
+      }
+    }
+  }
+}
+`)[0]
+	var buf bytes.Buffer
+	doListTypes(&buf, []*yang.Entry{e})
+	want := `enumeration          1
+string               2
+uint32               3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/paranpen/yangc/pkg/yang"
 	"github.com/spf13/cobra"
@@ -13,47 +18,166 @@ import (
 var mainCmd = &cobra.Command{
 	Use:   os.Args[0],
 	Short: "Tool to translate Yang Models to Unit Data API",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		if indentString, err = parseIndent(indentFlag); err != nil {
+			return err
+		}
+		if err = checkSortFields(); err != nil {
+			return err
+		}
+		if err = checkMaxNameLength(); err != nil {
+			return err
+		}
+		if err = checkEnumStyle(); err != nil {
+			return err
+		}
+		if err = checkCommentStyle(); err != nil {
+			return err
+		}
+		if err = checkOnUnknownType(); err != nil {
+			return err
+		}
+		return checkErrorFormat()
+	},
 }
 
-var yangFileName string
+var (
+	yangFileName string
+	indentFlag   string
+	onlyModules  []string
+)
+
+// indentString is one level of indentation in the generated output.
+var indentString = "  "
 
 func init() {
-	mainCmd.PersistentFlags().StringVarP(&yangFileName, "file", "f", "test.yang", "yang file name")
+	mainCmd.PersistentFlags().StringVarP(&yangFileName, "file", "f", "", "yang file name, the module is read from stdin if not set")
+	mainCmd.PersistentFlags().StringVar(&indentFlag, "indent", "2", "indentation of generated output: number of spaces or \"tab\"")
+	mainCmd.PersistentFlags().BoolVar(&yang.ParseOptions.AllowEnumAliases, "allow-enum-aliases", false, "allow enum members to share a value, making the later members aliases")
+	mainCmd.PersistentFlags().StringSliceVar(&onlyModules, "only-module", nil, "comma separated list of the modules to generate output for")
+}
+
+// parseIndent returns the indentation string described by s, which is
+// either "tab" or the number of spaces to indent by.
+func parseIndent(s string) (string, error) {
+	if s == "tab" {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("invalid indent %q: want a number of spaces or \"tab\"", s)
+	}
+	return strings.Repeat(" ", n), nil
 }
 
 func main() {
 	if _, err := mainCmd.ExecuteC(); err != nil {
+		if len(diagnosticCounts) > 0 {
+			exitWithSummary()
+		}
 		os.Exit(-1)
 	}
+	if diagnosticCounts["error"] == 0 {
+		writeGeneratedSummary(diagOut)
+	}
+	if len(diagnosticCounts) > 0 {
+		exitWithSummary()
+	}
 }
 
+// doCompile compiles fileName for a command's Run function, exiting with the
+// summary of the diagnostics if there were any errors.
 func doCompile(fileName string) []*yang.Entry {
-	var entries []*yang.Entry
+	entries, errs := compile(fileName)
+	exitIfError(errs)
+	return entries
+}
 
+// errNoInput is returned by compile when there is neither a file name nor
+// a module on stdin.
+var errNoInput = errors.New("no input: provide -f or pipe YANG to stdin")
+
+// stdin makes testing of compile easier.
+var stdin io.Reader = os.Stdin
+
+// compile reads, or fetches, fileName and returns the entries of its top
+// level modules along with any errors found.  When fileName is empty the
+// module is read from stdin instead.  Unlike doCompile it never exits.
+func compile(fileName string) ([]*yang.Entry, []error) {
+	ms, errs := readModules(fileName)
+	if len(errs) == 0 {
+		errs = readPinnedRevisions(ms)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return moduleEntries(ms)
+}
+
+// readModules reads, or fetches, fileName, or stdin when fileName is empty,
+// and returns the parsed modules.  Nothing is resolved, so the imports of
+// the modules need not be found.
+func readModules(fileName string) (*yang.Modules, []error) {
 	ms := yang.NewModules()
 	files := make([]string, 0, 10)
-	files = append(files, fileName)
+	if fileName != "" {
+		files = append(files, fileName)
+	}
 
+	var errs []error
 	if len(files) == 0 {
-		data, err := ioutil.ReadAll(os.Stdin)
+		// Do not wait for a module to be typed at a terminal.
+		if f, ok := stdin.(*os.File); ok {
+			if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+				return nil, []error{errNoInput}
+			}
+		}
+		data, err := ioutil.ReadAll(stdin)
+		if err == nil && len(bytes.TrimSpace(data)) == 0 {
+			err = errNoInput
+		}
 		if err == nil {
 			err = ms.Parse(string(data), "<STDIN>")
 		}
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			return nil, []error{err}
 		}
 	}
 
 	for _, name := range files {
+		if isURL(name) {
+			data, err := fetchURL(name)
+			if err == nil {
+				err = ms.Parse(data, name)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
 		if err := ms.Read(name); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+			errs = append(errs, err)
 			continue
 		}
 	}
+	return ms, errs
+}
 
-	// Process the read files, exiting if any errors were found.
-	exitIfError(ms.Process())
+// moduleEntries processes ms and returns the entries of its top level
+// modules, sorted by module name.  With --merge a single entry combining
+// the modules is returned instead.  No entries are returned if any errors
+// were found.
+func moduleEntries(ms *yang.Modules) ([]*yang.Entry, []error) {
+	// Process the read files, stopping if any errors were found.  With
+	// --best-effort the unresolved imports and types are only warned about.
+	ms.BestEffort = bestEffort
+	recovered, errs := recoverable(ms.Process())
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	for _, err := range recovered {
+		warn(err)
+	}
 
 	// Keep track of the top level modules we read in.
 	// Those are the only modules we want to print below.
@@ -67,21 +191,77 @@ func doCompile(fileName string) []*yang.Entry {
 		}
 	}
 	sort.Strings(names)
-	entries = make([]*yang.Entry, len(names))
-	for x, n := range names {
+	names, err := selectModules(names, onlyModules)
+	if err != nil {
+		return nil, []error{err}
+	}
+	entries := make([]*yang.Entry, 0, len(names))
+	for _, n := range names {
 		// yang.PrintNode(os.Stdout, mods[n])
-		entries[x] = yang.ToEntry(mods[n])
+		e := toEntry(mods[n])
+		if e == nil {
+			warn(fmt.Errorf("%s: module %s has no entries, skipping it", yang.Source(mods[n]), n))
+			continue
+		}
+		if len(recovered) > 0 {
+			fillUnresolved(e)
+		}
+		if failOnUnknownExt {
+			if errs := unknownExtensions(e); len(errs) > 0 {
+				return nil, errs
+			}
+		}
+		if strict {
+			if errs := versionErrors(e); len(errs) > 0 {
+				return nil, errs
+			}
+		}
+		entries = append(entries, e)
 	}
-	return entries
+	if mergeModules && len(entries) > 0 {
+		merged, errs := mergeEntries(entries)
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		entries = []*yang.Entry{merged}
+	}
+	return entries, nil
 }
 
-// exitIfError writes errs to standard error and exits with an exit status of 1.
+// selectModules returns the members of names that are listed in only, or
+// names if only is empty.  It is an error for only to list a module that is
+// not in names.
+func selectModules(names, only []string) ([]string, error) {
+	if len(only) == 0 {
+		return names, nil
+	}
+	want := map[string]bool{}
+	for _, n := range only {
+		want[n] = true
+	}
+	var selected []string
+	for _, n := range names {
+		if want[n] {
+			selected = append(selected, n)
+			delete(want, n)
+		}
+	}
+	for _, n := range only {
+		if want[n] {
+			return nil, fmt.Errorf("unknown module %q in --only-module", n)
+		}
+	}
+	return selected, nil
+}
+
+// toEntry makes testing of moduleEntries easier.
+var toEntry = func(m *yang.Module) *yang.Entry { return yang.ToEntry(m) }
+
+// exitIfError reports errs and exits with the summary of all diagnostics.
 // If errs is empty then exitIfError does nothing and simply returns.
 func exitIfError(errs []error) {
 	if len(errs) > 0 {
-		for _, err := range errs {
-			fmt.Fprintln(os.Stderr, err)
-		}
-		os.Exit(1)
+		reportErrors(errs)
+		exitWithSummary()
 	}
 }
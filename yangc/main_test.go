@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// compileString parses and processes the YANG source src, named name, and
+// returns the entries of its top level modules.
+func compileString(t *testing.T, name, src string) []*yang.Entry {
+	ms := yang.NewModules()
+	if err := ms.Parse(src, name); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	entries, errs := moduleEntries(ms)
+	if len(errs) > 0 {
+		t.Fatalf("%s: %v", name, errs)
+	}
+	return entries
+}
+
+// compileStrings is like compileString but parses every module in srcs,
+// which maps module names to their YANG source.
+func compileStrings(t *testing.T, srcs map[string]string) []*yang.Entry {
+	ms := yang.NewModules()
+	for name, src := range srcs {
+		if err := ms.Parse(src, name+".yang"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	entries, errs := moduleEntries(ms)
+	if len(errs) > 0 {
+		t.Fatalf("%v", errs)
+	}
+	return entries
+}
+
+func TestParseIndent(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "2", want: "  "},
+		{in: "4", want: "    "},
+		{in: "0", want: ""},
+		{in: "tab", want: "\t"},
+		{in: "-1", err: true},
+		{in: "wide", err: true},
+	} {
+		got, err := parseIndent(tt.in)
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("parseIndent(%q) succeeded, want error", tt.in)
+		case !tt.err && err != nil:
+			t.Errorf("parseIndent(%q): %v", tt.in, err)
+		case got != tt.want:
+			t.Errorf("parseIndent(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestModuleEntriesNil(t *testing.T) {
+	defer func(f func(*yang.Module) *yang.Entry) { toEntry = f }(toEntry)
+	defer func(w io.Writer) { diagOut = w }(diagOut)
+
+	toEntry = func(m *yang.Module) *yang.Entry {
+		if m.Name == "broken" {
+			return nil
+		}
+		return yang.ToEntry(m)
+	}
+	var warnings bytes.Buffer
+	diagOut = &warnings
+
+	entries := compileStrings(t, map[string]string{
+		"broken": `module broken { namespace "urn:broken"; prefix "b"; leaf l { type string; } }`,
+		"fine":   `module fine { namespace "urn:fine"; prefix "f"; leaf l { type string; } }`,
+	})
+	if len(entries) != 1 || entries[0].Name != "fine" {
+		t.Fatalf("got entries %v, want only fine", entries)
+	}
+	if want := "warning: broken.yang:1:1: module broken has no entries, skipping it\n"; warnings.String() != want {
+		t.Errorf("got warnings %q, want %q", warnings.String(), want)
+	}
+
+	// The generators must cope with nil entries.
+	entries = append(entries, nil)
+	for _, gen := range []func(io.Writer, []*yang.Entry){doProto, doHeader, doType, doTable, doTree, doTypeScript, doCapnp, doGraph, doListTypes} {
+		gen(ioutil.Discard, entries)
+	}
+}
+
+func TestOnlyModule(t *testing.T) {
+	defer func(m []string) { onlyModules = m }(onlyModules)
+	onlyModules = []string{"beta"}
+
+	srcs := map[string]string{}
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		srcs[name] = `
+module ` + name + ` {
+  namespace "urn:` + name + `";
+  prefix "` + name + `";
+  leaf ` + name + `-name { type string; }
+}
+`
+	}
+	var buf bytes.Buffer
+	doProto(&buf, compileStrings(t, srcs))
+	out := buf.String()
+	if !strings.Contains(out, `module "beta"`) {
+		t.Errorf("module beta missing from output:\n%s", out)
+	}
+	for _, name := range []string{`module "alpha"`, `module "gamma"`} {
+		if strings.Contains(out, name) {
+			t.Errorf("unselected module generated %s:\n%s", name, out)
+		}
+	}
+}
+
+func TestSelectModules(t *testing.T) {
+	names := []string{"alpha", "beta", "gamma"}
+	for _, tt := range []struct {
+		only []string
+		want []string
+		err  bool
+	}{
+		{nil, names, false},
+		{[]string{"gamma", "alpha"}, []string{"alpha", "gamma"}, false},
+		{[]string{"beta", "delta"}, nil, true},
+	} {
+		got, err := selectModules(names, tt.only)
+		if (err != nil) != tt.err {
+			t.Errorf("%v: got error %v, want error %v", tt.only, err, tt.err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.only, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	defer func(f func(int)) { exit = f }(exit)
+	exit = func(c int) { t.Fatalf("compile exited with status %d", c) }
+
+	dir, err := ioutil.TempDir("", "yangc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bad := filepath.Join(dir, "bad.yang")
+	src := `module bad { namespace "urn:bad"; prefix "b"; leaf l { type no-such-type; } }`
+	if err := ioutil.WriteFile(bad, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{bad, filepath.Join(dir, "missing.yang")} {
+		entries, errs := compile(name)
+		if len(errs) == 0 || entries != nil {
+			t.Errorf("%s: got entries %v and errors %v, want only errors", filepath.Base(name), entries, errs)
+		}
+	}
+}
+
+func TestCompileStdin(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+
+	stdin = strings.NewReader("\n")
+	if _, errs := compile(""); len(errs) != 1 || errs[0] != errNoInput {
+		t.Errorf("got errors %v, want %v", errs, errNoInput)
+	}
+
+	stdin = strings.NewReader(`module piped { namespace "urn:piped"; prefix "p"; container c { leaf l { type string; } } }`)
+	entries, errs := compile("")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(entries) != 1 || entries[0].Name != "piped" {
+		t.Errorf("got entries %v, want module piped", entries)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// mergeModules combines all top level modules into a single tree.
+var mergeModules bool
+
+// mergedName is the name of the tree built by --merge.
+const mergedName = "merged"
+
+func init() {
+	mainCmd.PersistentFlags().BoolVar(&mergeModules, "merge", false, "combine all modules into a single tree")
+}
+
+// mergeEntries returns a single entry holding the top level nodes of all
+// modules in entries.  Augments and imports have already been resolved in
+// entries so each node is complete.  The nodes keep their parent module and
+// their own node, the merged entry has a module node of its own that is
+// not part of any module set.  It is an error for two modules to have a top
+// level node of the same name.
+func mergeEntries(entries []*yang.Entry) (*yang.Entry, []error) {
+	merged := &yang.Entry{
+		Node:  &yang.Module{Name: mergedName},
+		Name:  mergedName,
+		Kind:  yang.DirectoryEntry,
+		Dir:   map[string]*yang.Entry{},
+		Extra: map[string][]interface{}{},
+	}
+	var names []string
+	var errs []error
+	for _, e := range entries {
+		names = append(names, e.Name)
+		for _, se := range sortedDir(e) {
+			if o := merged.Dir[se.Name]; o != nil {
+				errs = append(errs, fmt.Errorf("%s: %s is defined by both module %s and module %s", yang.Source(se.Node), se.Name, o.Parent.Name, e.Name))
+				continue
+			}
+			merged.Dir[se.Name] = se
+		}
+	}
+	merged.Description = "Merged from modules " + strings.Join(names, ", ") + "."
+	return merged, errs
+}
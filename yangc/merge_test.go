@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+var mergeTestModules = map[string]string{
+	"system": `
+module system {
+  namespace "urn:system";
+  prefix "sys";
+  container system { leaf hostname { type string; } }
+}
+`,
+	"interfaces": `
+module interfaces {
+  namespace "urn:interfaces";
+  prefix "if";
+  container interfaces { leaf count { type uint32; } }
+}
+`,
+}
+
+func TestMerge(t *testing.T) {
+	defer func(b bool) { mergeModules = b }(mergeModules)
+	mergeModules = true
+
+	entries := compileStrings(t, mergeTestModules)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	out := buf.String()
+	if strings.Count(out, "package ") != 1 {
+		t.Errorf("want a single package:\n%s", out)
+	}
+	for _, want := range []string{"message System {", "message Interfaces {"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	srcs := map[string]string{
+		"a": `module a { namespace "urn:a"; prefix "a"; container system { } }`,
+		"b": `module b { namespace "urn:b"; prefix "b"; container system { } }`,
+	}
+	_, errs := mergeEntries(compileStrings(t, srcs))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "system is defined by both module a and module b") {
+		t.Errorf("got errors %v, want a conflict on system", errs)
+	}
+}
+
+func TestMergeNodes(t *testing.T) {
+	defer func(b bool) { mergeModules = b }(mergeModules)
+	mergeModules = true
+
+	merged := compileStrings(t, mergeTestModules)[0]
+	for name, module := range map[string]string{"system": "system", "interfaces": "interfaces"} {
+		if got := yang.RootNode(merged.Dir[name].Node).Name; got != module {
+			t.Errorf("%s: node from module %s, want %s", name, got, module)
+		}
+	}
+	if ns := yang.ModuleNamespace(merged); ns != "" {
+		t.Errorf("merged tree has namespace %s of a member", ns)
+	}
+	var buf bytes.Buffer
+	doGraph(&buf, []*yang.Entry{merged})
+	for _, want := range []string{`"system";`, `"interfaces";`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in graph:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// maxNameLength is the longest generated message or struct name allowed.
+// Longer names are truncated.  0 means there is no limit.
+var maxNameLength int
+
+// nameHashLength is the length of the suffix added to truncated names.
+const nameHashLength = len("_12345678")
+
+func init() {
+	mainCmd.PersistentFlags().IntVar(&maxNameLength, "max-name-length", 0, "truncate generated names longer than this, 0 for no limit")
+}
+
+// checkMaxNameLength returns an error if --max-name-length is too short to
+// hold a truncated name.
+func checkMaxNameLength() error {
+	if maxNameLength != 0 && maxNameLength <= nameHashLength {
+		return fmt.Errorf("invalid --max-name-length %d: want 0 or more than %d", maxNameLength, nameHashLength)
+	}
+	return nil
+}
+
+// limitName returns name if it is no longer than --max-name-length.
+// Otherwise it warns and returns name truncated to the limit, ending in
+// a hash of the full name so distinct long names remain distinct.
+func (pf *protofile) limitName(name string) string {
+	if maxNameLength == 0 || len(name) <= maxNameLength {
+		return name
+	}
+	if short, ok := pf.longNames[name]; ok {
+		return short
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	short := fmt.Sprintf("%s_%08x", name[:maxNameLength-nameHashLength], h.Sum32())
+	if pf.longNames == nil {
+		pf.longNames = map[string]string{}
+	}
+	pf.longNames[name] = short
+	warn(fmt.Errorf("name %s is longer than %d characters, using %s", name, maxNameLength, short))
+	return short
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMaxNameLength(t *testing.T) {
+	defer func(n int, q bool, w io.Writer) {
+		maxNameLength, qualifiedNames, diagOut = n, q, w
+	}(maxNameLength, qualifiedNames, diagOut)
+	maxNameLength = 20
+	qualifiedNames = true
+	var warnings bytes.Buffer
+	diagOut = &warnings
+
+	entries := compileString(t, "long.yang", `
+module long {
+  namespace "urn:long";
+  prefix "l";
+
+  container network-instances {
+    list network-instance {
+      key name;
+      leaf name { type string; }
+      container protocols {
+        leaf enabled { type boolean; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	const long = "NetworkInstancesNetworkInstanceProtocols"
+	if strings.Contains(buf.String(), long) {
+		t.Errorf("%s not truncated:\n%s", long, buf.String())
+	}
+	if n := strings.Count(warnings.String(), long+" "); n != 1 {
+		t.Errorf("%s warned about %d times, want once", long, n)
+	}
+	short := (&protofile{}).limitName(long)
+	if len(short) != maxNameLength {
+		t.Errorf("got %s, want %d characters", short, maxNameLength)
+	}
+	if want := "struct " + short + " {"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+	if want := "warning: name " + long + " is longer than 20 characters, using " + short + "\n"; !strings.Contains(warnings.String(), want) {
+		t.Errorf("got warnings:\n%s\nwant %q", warnings.String(), want)
+	}
+}
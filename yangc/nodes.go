@@ -14,7 +14,9 @@ func init() {
 		Run: func(cmd *cobra.Command, args []string) {
 			entries := doCompile(yangFileName)
 			for _, e := range entries {
-				yang.PrintNode(os.Stdout, e.Node)
+				if e != nil {
+					yang.PrintNode(os.Stdout, e.Node)
+				}
 			}
 		},
 	}
@@ -18,6 +18,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"os"
 	"path/filepath"
@@ -44,6 +45,31 @@ var (
 	protoFlat       bool
 	protoPreserve   string
 	protoWithSource bool
+	qualifiedNames  bool
+	stableTags      bool
+
+	// caseSensitiveDedup treats names that differ only in case as
+	// distinct when checking generated names for collisions.
+	caseSensitiveDedup = true
+
+	// keepCase keeps the case of enum members in generated constants.
+	keepCase bool
+
+	// onUnknownType is what happens to a field whose type kind has no
+	// mapping, see protoKind.
+	onUnknownType = "warn"
+
+	// enumUnset inserts an explicit X_UNSET = 0 member into enums that
+	// have no member with the value 0.
+	enumUnset bool
+
+	// proto3Optional marks leaves without a default that need not be
+	// set as optional so that their presence is tracked.
+	proto3Optional bool
+
+	// mergeEnums writes the inline enums of a message that have the same
+	// members once, shared by their fields.
+	mergeEnums bool
 )
 
 func init() {
@@ -56,30 +82,54 @@ func init() {
 		},
 	}
 	mainCmd.AddCommand(protoCmd)
+	mainCmd.PersistentFlags().BoolVar(&qualifiedNames, "qualified-names", false, "name messages after their full schema path")
+	mainCmd.PersistentFlags().BoolVar(&caseSensitiveDedup, "case-sensitive-dedup", true, "consider names differing only in case distinct when checking for collisions")
+	mainCmd.PersistentFlags().StringVar(&onUnknownType, "on-unknown-type", "warn", "what to do with a leaf whose type cannot be mapped: error, warn or bytes (fall back to bytes, or char * in C, silently)")
+	mainCmd.PersistentFlags().BoolVar(&keepCase, "keep-case", false, "keep the case of enum member names instead of upper casing them")
+	protoCmd.Flags().BoolVar(&stableTags, "stable-tags", false, "derive field tags from a hash of the field name")
+	protoCmd.Flags().BoolVar(&proto3Optional, "proto3-optional", false, "mark non-mandatory leaves without a default optional to track their presence")
+	protoCmd.Flags().StringVar(&tagMapFile, "tag-map", "", "write a JSON object mapping the schema path of each leaf to its field tag to this file")
+	protoCmd.Flags().BoolVar(&enumUnset, "enum-unset", false, "add an explicit X_UNSET = 0 member to enums without a zero value")
+	protoCmd.Flags().BoolVar(&mergeEnums, "merge-enums", false, "write identical inline enums of a message once and share them between fields")
 }
 
 // A protofile collects the produced proto along with meta information.
 type protofile struct {
-	buf          bytes.Buffer
 	fixedNames   map[string]string // maps a fixed name back to its origial name.
 	errs         []error
 	messages     map[string]*messageInfo
 	hasDecimal64 bool
+	stableTags   bool                   // derive tags from field names, see messageInfo.tag
+	longNames    map[string]string      // truncated names, see limitName
+	types        int                    // top-level types written, see countGenerated
+	tagMap       map[string]interface{} // leaf tags by schema path, see recordTag
+	structs      []string               // C structs written, see declare
+	globals      map[string]*yang.Entry // file scope C identifiers, see global
+	masks        bytes.Buffer           // C bit mask defines, see writeBitMasks
 }
 
 // A messageInfo contains tag information about fields in a message.
 type messageInfo struct {
 	last   int
 	fields map[string]int
+	used   map[int]bool // tags in fields, so finding a free tag is O(1)
+	stable bool
+	enums  map[string]string // enum kinds by their members, see enumKey
 }
 
+// doProto writes the proto for each of entries to w, or to the file named
+// by the module's option.  Errors are reported as diagnostics, which main
+// summarizes in the exit status, rather than stopping the other modules.
 func doProto(w io.Writer, entries []*yang.Entry) {
-	failed := false
 	if protoPreserve != "" && protoPreserve[0] != '.' {
 		protoPreserve = "." + protoPreserve
 	}
+	var tags map[string]interface{}
+	if tagMapFile != "" {
+		tags = map[string]interface{}{}
+	}
 	for _, e := range entries {
-		if len(e.Dir) == 0 {
+		if e == nil || len(e.Dir) == 0 {
 			// skip modules that have nothing in them
 			continue
 		}
@@ -87,6 +137,8 @@ func doProto(w io.Writer, entries []*yang.Entry) {
 		pf := &protofile{
 			fixedNames: map[string]string{},
 			messages:   map[string]*messageInfo{},
+			stableTags: stableTags,
+			tagMap:     tags,
 		}
 
 		var out string
@@ -97,109 +149,128 @@ func doProto(w io.Writer, entries []*yang.Entry) {
 				err = pf.importTags(fd)
 				fd.Close()
 				if err != nil {
-					failed = true
-					fmt.Fprintln(os.Stderr, err)
+					reportError(err)
 					continue
 				}
 			}
 		}
 
-		pf.printHeader(&pf.buf, e, true)
-		for _, e := range flatten(e) {
-			pf.printService(&pf.buf, e)
-		}
-		for _, child := range children(e) {
-			if protoFlat {
-				for _, e := range flatten(child) {
-					fmt.Fprintln(&pf.buf)
-					pf.printNode(&pf.buf, e, false)
+		// The proto is streamed to its destination rather than held
+		// in memory.  A file is written under a temporary name and
+		// only renamed into place once it was generated without
+		// errors.
+		var fd *os.File
+		var bw *bufio.Writer
+		if out == "" {
+			out = "stdout"
+			bw = bufio.NewWriter(w)
+		} else {
+			// The temporary name is unique so concurrent runs
+			// writing the same file do not clobber each other.
+			var err error
+			if fd, err = os.CreateTemp(filepath.Dir(out), filepath.Base(out)+".*.tmp"); err == nil {
+				if err = fd.Chmod(0644); err != nil {
+					fd.Close()
+					os.Remove(fd.Name())
 				}
-			} else {
-				fmt.Fprintln(&pf.buf)
-				pf.printNode(&pf.buf, child, true)
 			}
-		}
-		if pf.hasDecimal64 {
-			prefix := " "
-			if proto2 {
-				prefix = "  optional"
+			if err != nil {
+				reportError(err)
+				continue
 			}
-			fmt.Fprintf(&pf.buf, `
-// A Decimal64 is the YANG decimal64 type.
-message Decimal64 {
-%s int64  value = 1;            // integeral value
-%s uint32 fraction_digits = 2;  // decimal point position [1..18]
-}
-`, prefix, prefix)
-		}
-		pf.dumpMessageInfo()
-		if len(pf.errs) != 0 {
-			for _, err := range pf.errs {
-				failed = true
-				fmt.Fprintf(os.Stderr, "%s: %v\n", e.Name, err)
+			bw = bufio.NewWriter(fd)
+		}
+		pf.printProto(bw, e)
+		countGenerated(pf)
+		err := bw.Flush()
+		if fd != nil {
+			if cerr := fd.Close(); err == nil {
+				err = cerr
 			}
+		}
+		if err != nil {
+			reportError(fmt.Errorf("%s: %v", out, err))
+		}
+		for _, err := range pf.errs {
+			reportError(fmt.Errorf("%s: %v", e.Name, err))
+		}
+		if fd == nil {
 			continue
 		}
-		var closeme io.Closer
-		if out == "" {
-			out = "stdout"
-		} else {
-			var old string
-			if protoPreserve != "" {
-				old = out + protoPreserve
-				if _, err := os.Stat(out); err == nil {
-					if err := os.Rename(out, old); err != nil {
-						failed = true
-						fmt.Fprintf(os.Stderr, "%s: %v\n", e.Name, err)
-						continue
-					}
+		if err != nil || len(pf.errs) != 0 {
+			os.Remove(fd.Name())
+			continue
+		}
+		if protoPreserve != "" {
+			if _, err := os.Stat(out); err == nil {
+				if err := os.Rename(out, out+protoPreserve); err != nil {
+					reportError(fmt.Errorf("%s: %v", e.Name, err))
+					os.Remove(fd.Name())
+					continue
 				}
 			}
-			fd, err := os.Create(out)
-			if err != nil {
-				failed = true
-				fmt.Fprintln(os.Stderr, err)
-			}
-			w = fd
-			closeme = fd
 		}
-		if _, err := io.Copy(w, &pf.buf); err != nil {
-			failed = true
-			fmt.Fprintf(os.Stderr, "%s: %v\n", out, err)
+		if err := os.Rename(fd.Name(), out); err != nil {
+			reportError(fmt.Errorf("%s: %v", out, err))
+			os.Remove(fd.Name())
+		}
+	}
+	if tags != nil {
+		if err := writeTagMap(tags); err != nil {
+			reportError(err)
 		}
-		if closeme != nil {
-			if err := closeme.Close(); err != nil {
-				failed = true
-				fmt.Fprintf(os.Stderr, "%s: %v\n", out, err)
+	}
+}
+
+// printProto writes the proto for the module e to w.
+func (pf *protofile) printProto(w io.Writer, e *yang.Entry) {
+	pf.printHeader(w, e, true)
+	for _, e := range flatten(e) {
+		pf.printService(w, e)
+	}
+	for _, child := range children(e) {
+		if protoFlat {
+			for _, e := range flatten(child) {
+				fmt.Fprintln(w)
+				pf.printNode(w, e, false)
+				pf.types++
 			}
+		} else {
+			fmt.Fprintln(w)
+			pf.printNode(w, child, true)
+			pf.types++
 		}
 	}
-	if failed {
-		os.Exit(1)
+	if pf.hasDecimal64 {
+		pf.types++
+		prefix := " "
+		if proto2 {
+			prefix = indentString + "optional"
+		}
+		fmt.Fprintf(w, `
+// A Decimal64 is the YANG decimal64 type.
+message Decimal64 {
+%s int64  value = 1;            // integeral value
+%s uint32 fraction_digits = 2;  // decimal point position [1..18]
+}
+`, prefix, prefix)
 	}
+	pf.dumpMessageInfo(w)
 }
 
 // Children returns all the children nodes of e that are not RPC nodes.
 func children(e *yang.Entry) []*yang.Entry {
-	var names []string
-	for k, se := range e.Dir {
+	var children []*yang.Entry
+	for _, se := range e.Dir {
 		if se.RPC == nil && se.GetKind() != "Typedef" { // taewony
-			names = append(names, k)
+			children = append(children, se)
 		}
 	}
-	if len(names) == 0 {
-		return nil
-	}
-	// sort.Strings(names)
-	children := make([]*yang.Entry, len(names))
-	for x, n := range names {
-		children[x] = e.Dir[n]
-	}
+	sortEntries(children)
 	return children
 }
 
-func (pf *protofile) dumpMessageInfo() {
-	w := &pf.buf
+func (pf *protofile) dumpMessageInfo(w io.Writer) {
 	fmt.Fprint(w, `
 // Do not delete the lines below, they preserve tag information for goyang.
 `)
@@ -212,7 +283,7 @@ func (pf *protofile) dumpMessageInfo() {
 	sort.Strings(names)
 	for _, name := range names {
 		mi := pf.messages[name]
-		mi.dump(name, &pf.buf)
+		mi.dump(name, w)
 	}
 }
 
@@ -253,21 +324,26 @@ func (pf *protofile) importTags(r io.Reader) error {
 		if err != nil {
 			return fmt.Errorf("invalid goyang-tag: %s", line)
 		}
-		mi := pf.messages[fields[0]]
-		if mi == nil {
-			mi = &messageInfo{
-				fields: map[string]int{},
-			}
-			pf.messages[fields[0]] = mi
-		}
-		mi.fields[fields[1]] = tag
-		if mi.last < tag {
-			mi.last = tag
-		}
+		pf.messageTags(fields[0]).set(fields[1], tag)
 	}
 	return s.Err()
 }
 
+// messageTags returns the tag information for the message name, creating it
+// if needed.
+func (pf *protofile) messageTags(name string) *messageInfo {
+	mi := pf.messages[name]
+	if mi == nil {
+		mi = &messageInfo{
+			fields: map[string]int{},
+			used:   map[int]bool{},
+			stable: pf.stableTags,
+		}
+		pf.messages[name] = mi
+	}
+	return mi
+}
+
 func (m *messageInfo) tag(name, kind string, isList bool) int {
 	key := name + "/" + kind
 	if isList {
@@ -276,11 +352,40 @@ func (m *messageInfo) tag(name, kind string, isList bool) int {
 	if i := m.fields[key]; i != 0 {
 		return i
 	}
-	m.last++
-	m.fields[key] = m.last
+	if m.stable {
+		return m.stableTag(key, name)
+	}
+	m.set(key, m.last+1)
 	return m.last
 }
 
+// set records tag as the tag of the field key.
+func (m *messageInfo) set(key string, tag int) {
+	m.fields[key] = tag
+	m.used[tag] = true
+	if m.last < tag {
+		m.last = tag
+	}
+}
+
+// maxStableTag is the largest tag assigned by stableTag.  It keeps the
+// tags below 19000, the first tag reserved by protocol buffers.
+const maxStableTag = 18999
+
+// stableTag assigns key a tag derived from a hash of name, so the tag does
+// not depend on the order fields are added in.  On a collision the next
+// unused tag is taken.
+func (m *messageInfo) stableTag(key, name string) int {
+	h := fnv.New32a()
+	io.WriteString(h, name)
+	tag := int(h.Sum32()%maxStableTag) + 1
+	for m.used[tag] {
+		tag = tag%maxStableTag + 1
+	}
+	m.set(key, tag)
+	return tag
+}
+
 // kind2proto maps base yang types to protocol buffer types.
 // TODO(borman): do TODO types.
 var kind2proto = map[yang.TypeKind]string{
@@ -306,6 +411,47 @@ var kind2proto = map[yang.TypeKind]string{
 	yang.Yunion:              "INLINE-union", // handled inline
 }
 
+// protoKind returns the type of values of kind k, the type of the field
+// for se.  A kind with no mapping falls back to bytes, see lookupKind, with
+// a comment prefixed by ind written to w when it is reported.
+func protoKind(w io.Writer, ind string, se *yang.Entry, k yang.TypeKind) string {
+	kind, reported := lookupKind(kind2proto, "bytes", se, k)
+	if reported {
+		fmt.Fprintf(w, "%s// *WARNING* unknown type kind %s\n", ind, k)
+	}
+	return kind
+}
+
+// lookupKind returns the type kinds maps k to, the kind of the type of se.
+// A kind with no mapping, as found on a partially resolved entry, falls back
+// to fallback.  Depending on --on-unknown-type this is reported as an error
+// or a warning, and reported is true, or it is not reported at all.
+func lookupKind(kinds map[yang.TypeKind]string, fallback string, se *yang.Entry, k yang.TypeKind) (kind string, reported bool) {
+	if kind, ok := kinds[k]; ok {
+		return kind, false
+	}
+	err := fmt.Errorf("%s: %s has unknown type kind %s", yang.Source(se.Node), se.Name, k)
+	switch onUnknownType {
+	case "bytes":
+		return fallback, false
+	case "error":
+		reportError(err)
+	default:
+		warn(err)
+	}
+	return fallback, true
+}
+
+// checkOnUnknownType returns an error if --on-unknown-type is not a known
+// mode.
+func checkOnUnknownType() error {
+	switch onUnknownType {
+	case "error", "warn", "bytes":
+		return nil
+	}
+	return fmt.Errorf("invalid --on-unknown-type %q: want error, warn or bytes", onUnknownType)
+}
+
 func isStream(e *yang.Entry) bool {
 	for _, ext := range e.Exts {
 		if ext.Kind() == "grpc:stream" {
@@ -315,9 +461,12 @@ func isStream(e *yang.Entry) bool {
 	return false
 }
 
+// now makes testing of printHeader easier.
+var now = time.Now
+
 func (pf *protofile) printHeader(w io.Writer, e *yang.Entry, isProtoFormat bool) {
 	fmt.Fprintf(w, "// Automatically generated by yangc\n")
-	fmt.Fprintf(w, "// compiled %s\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "// compiled %s\n", now().UTC().Format(time.RFC3339))
 
 	fmt.Fprintf(w, "// module %q\n", e.Name) // module
 
@@ -327,8 +476,8 @@ func (pf *protofile) printHeader(w io.Writer, e *yang.Entry, isProtoFormat bool)
 		}
 	}
 
-	if v := e.Extra["namespace"]; len(v) > 0 {
-		fmt.Fprintf(w, "// namespace %q\n", v[0].(*yang.Value).Name) // namespace from Extra
+	if ns := yang.ModuleNamespace(e); ns != "" {
+		fmt.Fprintf(w, "// namespace %q\n", ns)
 	}
 	fmt.Fprintln(w)
 	if !protoNoComments && e.Description != "" {
@@ -374,16 +523,16 @@ func (pf *protofile) printService(w io.Writer, e *yang.Entry) {
 			}
 		}
 		needEmpty = needEmpty || rpc.Input == nil || rpc.Output == nil
-		fmt.Fprintf(w, "  rpc %s (%s) returns (%s);\n", k, iName, oName)
+		fmt.Fprintf(w, "%srpc %s (%s) returns (%s);\n", indentString, k, iName, oName)
 	}
 	fmt.Fprintln(w, "}")
 	for _, k := range names {
 		rpc := e.Dir[k].RPC
 		if rpc.Input != nil {
-			pf.printNode(w, rpc.Input, false)
+			pf.printRPCMessage(w, rpc.Input)
 		}
 		if rpc.Output != nil {
-			pf.printNode(w, rpc.Output, false)
+			pf.printRPCMessage(w, rpc.Output)
 		}
 	}
 
@@ -392,148 +541,214 @@ func (pf *protofile) printService(w io.Writer, e *yang.Entry) {
 	}
 }
 
+// printRPCMessage writes the input or output e of an RPC to w.  Containers,
+// lists, anydata and anyxml nodes in e are generated the same as they are
+// in the data tree.
+func (pf *protofile) printRPCMessage(w io.Writer, e *yang.Entry) {
+	if protoFlat {
+		for _, e := range flatten(e) {
+			fmt.Fprintln(w)
+			pf.printNode(w, e, false)
+		}
+		return
+	}
+	pf.printNode(w, e, true)
+}
+
 // printNode writes e, formatted almost like a protobuf message, to w.
 func (pf *protofile) printNode(w io.Writer, e *yang.Entry, nest bool) {
-	if !protoNoComments && e.Description != "" {
-		fmt.Fprintln(indent.NewWriter(w, "// "), e.Description)
+	if !protoNoComments {
+		if e.Description != "" {
+			fmt.Fprintln(indent.NewWriter(w, "// "), e.Description)
+		}
+		writeReference(w, "", e)
 	}
 
+	ind := indentString
+	ind2 := ind + ind
+
 	messageName := pf.fullName(e)
-	mi := pf.messages[messageName]
-	if mi == nil {
-		mi = &messageInfo{
-			fields: map[string]int{},
-		}
-		pf.messages[messageName] = mi
-	}
+	mi := pf.messageTags(messageName)
 
 	fmt.Fprintf(w, "message %s {", pf.messageName(e)) // matching brace }
 	if protoWithSource {
 		fmt.Fprintf(w, " // %s", yang.Source(e.Node))
 	}
 	fmt.Fprintln(w)
+	writeMountPoint(w, ind, e)
+	writeExtensions(w, ind, e)
 
 	nodes := children(e)
 	for i, se := range nodes {
 		k := se.Name
-		if !protoNoComments && se.Description != "" {
-			fmt.Fprintln(indent.NewWriter(w, "  // "), se.Description)
+		if !protoNoComments {
+			if se.Description != "" {
+				fmt.Fprintln(indent.NewWriter(w, ind+"// "), se.Description)
+			}
+			writeReference(w, ind, se)
+			if se.Type != nil {
+				writeExtensions(w, ind, se)
+			}
 		}
 		if nest && (len(se.Dir) > 0 || se.Type == nil) {
-			pf.printNode(indent.NewWriter(w, "  "), se, true)
+			pf.printNode(indent.NewWriter(w, ind), se, true)
 		}
-		prefix := "  "
+		prefix := ind
 		if se.ListAttr != nil {
-			prefix = "  repeated "
-		} else if proto2 {
-			prefix = "  optional "
+			prefix = ind + "repeated "
+		} else if proto2 || (proto3Optional && hasPresence(se)) {
+			prefix = ind + "optional "
 		}
 		name := pf.fieldName(k)
 		printed := false
 		var kind string
-		if len(se.Dir) > 0 || se.Type == nil {
+		var oneofTags map[string]int // tags of the members of a oneof
+		st := fieldType(se)
+		if len(se.Dir) > 0 || st == nil {
 			kind = pf.messageName(se)
-		} else if se.Type.Kind == yang.Ybits {
-			values := dedup(se.Type.Bit.Values())
+		} else if st.Kind == yang.Ybits {
+			values := dedup(st.Bit.Values())
 			asComment := false
 			switch {
 			case len(values) > 0 && values[len(values)-1] > 63:
 				if i != 0 {
 					fmt.Fprintln(w)
 				}
-				fmt.Fprintf(w, "  // *WARNING* bitfield %s has more than 64 positions\n", name)
+				fmt.Fprintf(w, "%s// *WARNING* bitfield %s has more than 64 positions\n", ind, name)
+				warn(fmt.Errorf("%s: bitfield %s has more than 64 positions", yang.Source(se.Node), se.Name))
 				kind = "uint64"
 				asComment = true
 			case len(values) > 0 && values[len(values)-1] > 31:
 				if i != 0 {
 					fmt.Fprintln(w)
 				}
-				fmt.Fprintf(w, "  // bitfield %s to large for enum\n", name)
+				fmt.Fprintf(w, "%s// bitfield %s to large for enum\n", ind, name)
 				kind = "uint64"
 				asComment = true
 			default:
 				kind = pf.fixName(se.Name)
 			}
 			if !asComment {
-				fmt.Fprintf(w, "  enum %s {\n", kind)
-				fmt.Fprintf(w, "    %s_FIELD_NOT_SET = 0;\n", kind)
+				fmt.Fprintf(w, "%senum %s {\n", ind, kind)
+				fmt.Fprintf(w, "%s%s_FIELD_NOT_SET = 0;\n", ind2, kind)
 			} else {
-				fmt.Fprintf(w, "  // Values:\n")
+				fmt.Fprintf(w, "%s// Values:\n", ind)
 			}
 			names := map[int64][]string{}
-			for n, v := range se.Type.Bit.NameMap() {
+			for n, v := range st.Bit.NameMap() {
 				names[v] = append(names[v], n)
 			}
+			seen := map[string]string{}
 			for _, v := range values {
 				ns := names[v]
 				sort.Strings(ns)
 				if asComment {
 					for _, n := range ns {
-						fmt.Fprintf(w, "  //   %s = 1 << %d\n", n, v)
+						fmt.Fprintf(w, "%s//   %s = 1 << %d\n", ind, n, v)
 					}
 				} else {
-					n := strings.ToUpper(pf.fieldName(ns[0]))
-					fmt.Fprintf(w, "    %s_%s = %d;\n", kind, n, 1<<uint(v))
+					n := pf.enumMemberName(se.Node, kind, seen, ns[0])
+					fmt.Fprintf(w, "%s%s_%s = %d;\n", ind2, kind, n, 1<<uint(v))
 					for _, n := range ns[1:] {
-						n = strings.ToUpper(pf.fieldName(n))
-						fmt.Fprintf(w, "    // %s = %d; (DUPLICATE VALUE)\n", n, 1<<uint(v))
+						n = pf.memberName(n)
+						fmt.Fprintf(w, "%s// %s = %d; (DUPLICATE VALUE)\n", ind2, n, 1<<uint(v))
 					}
 				}
 			}
 			if !asComment {
-				fmt.Fprintf(w, "  };\n")
+				fmt.Fprintf(w, "%s};\n", ind)
 			}
-		} else if se.Type.Kind == yang.Ydecimal64 {
+		} else if st.Kind == yang.Ydecimal64 {
 			kind = "Decimal64"
 			pf.hasDecimal64 = true
-		} else if se.Type.Kind == yang.Yenum {
+		} else if st.Kind == yang.Yenum && mergeEnums && mi.enums[enumKey(st)] != "" {
+			kind = mi.enums[enumKey(st)]
+		} else if st.Kind == yang.Yenum {
 			kind = pf.fixName(se.Name)
-			fmt.Fprintf(w, "  enum %s {", kind)
+			if mergeEnums {
+				if mi.enums == nil {
+					mi.enums = map[string]string{}
+				}
+				mi.enums[enumKey(st)] = kind
+			}
+			fmt.Fprintf(w, "%senum %s {", ind, kind)
 			if protoWithSource {
 				fmt.Fprintf(w, " // %s", yang.Source(se.Node))
 			}
 			fmt.Fprintln(w)
 
-			for i, n := range se.Type.Enum.Names() {
-				fmt.Fprintf(w, "    %s_%s = %d;\n", kind, strings.ToUpper(pf.fieldName(n)), i)
+			// Members keep their declared values and order,
+			// except that the member declared as 0 is the
+			// proto3 default and must come first.  With
+			// --enum-unset an enum that has no member declared
+			// as 0 gets an explicit UNSET default instead.
+			names := protoEnumNames(st.Enum)
+			if hasAliases(st.Enum) {
+				fmt.Fprintf(w, "%soption allow_alias = true;\n", ind2)
+			}
+			switch {
+			case st.Enum.Name(0) != "":
+			case enumUnset:
+				fmt.Fprintf(w, "%s%s_UNSET = 0;\n", ind2, kind)
+			default:
+				warn(fmt.Errorf("%s: enum %s has no member with the value 0, see --enum-unset", yang.Source(se.Node), se.Name))
+			}
+			seen := map[string]string{}
+			for _, n := range names {
+				fmt.Fprintf(w, "%s%s_%s = %d;\n", ind2, kind, pf.enumMemberName(se.Node, kind, seen, n), st.Enum.Value(n))
 			}
-			fmt.Fprintf(w, "  };\n")
-		} else if se.Type.Kind == yang.Yunion {
-			types := pf.unionTypes(se.Type, map[string]bool{})
+			fmt.Fprintf(w, "%s};\n", ind)
+		} else if st.Kind == yang.Yunion {
+			types := pf.unionTypes(se, st, map[string]bool{})
 			switch len(types) {
 			case 0:
-				fmt.Fprintf(w, "    // *WARNING* union %s has no types\n", se.Name)
+				fmt.Fprintf(w, "%s// *WARNING* union %s has no types\n", ind2, se.Name)
+				warn(fmt.Errorf("%s: union %s has no types", yang.Source(se.Node), se.Name))
 				printed = true
 			case 1:
 				kind = types[0]
 			default:
 				iw := w
+				oi := mi
 				kind = pf.fixName(se.Name)
 				if se.ListAttr != nil {
-					fmt.Fprintf(w, "  message %s {\n", kind)
-					iw = indent.NewWriter(w, "  ")
+					// A oneof cannot be repeated, so a list of
+					// unions is a repeated wrapper message, with
+					// its own tags, holding the oneof.
+					fmt.Fprintf(w, "%smessage %s {\n", ind, kind)
+					iw = indent.NewWriter(w, ind)
+					oi = pf.messageTags(messageName + "_" + kind)
 				}
-				fmt.Fprintf(iw, "  oneof %s {", kind) // matching brace }
+				fmt.Fprintf(iw, "%soneof %s {", ind, kind) // matching brace }
 				if protoWithSource {
-					fmt.Fprintf(w, " // %s", yang.Source(se.Node))
+					fmt.Fprintf(iw, " // %s", yang.Source(se.Node))
 				}
-				fmt.Fprintln(w)
+				fmt.Fprintln(iw)
+				// Each member of the oneof is a field
+				// with a tag of its own.
+				oneofTags = map[string]int{}
 				for _, tkind := range types {
-					fmt.Fprintf(iw, "    %s %s_%s = %d;\n", tkind, kind, tkind, mi.tag(name, tkind, false))
+					oneofTags[tkind] = oi.tag(name+"_"+tkind, tkind, false)
+					fmt.Fprintf(iw, "%s%s %s_%s = %d;\n", ind2, tkind, kind, tkind, oneofTags[tkind])
 				}
+				pf.recordUnionTags(se, oneofTags)
 				// { to match the brace below to keep brace matching working
-				fmt.Fprintf(iw, "  }\n")
+				fmt.Fprintf(iw, "%s}\n", ind)
 				if se.ListAttr != nil {
-					fmt.Fprintf(w, "  }\n")
+					fmt.Fprintf(w, "%s}\n", ind)
 				} else {
 					printed = true
 				}
 			}
 		} else {
-			kind = kind2proto[se.Type.Kind]
+			kind = protoKind(w, ind, se, st.Kind)
 		}
 		if !printed {
-			fmt.Fprintf(w, "%s%s %s = %d;", prefix, kind, name, mi.tag(name, kind, se.ListAttr != nil))
+			tag := mi.tag(name, kind, se.ListAttr != nil)
+			if oneofTags == nil {
+				pf.recordTag(se, tag)
+			}
+			fmt.Fprintf(w, "%s%s %s = %d%s;%s", prefix, kind, name, tag, protoDeprecated(se), fieldComment(se))
 			if protoWithSource {
 				fmt.Fprintf(w, " // %s", yang.Source(se.Node))
 			}
@@ -544,8 +759,57 @@ func (pf *protofile) printNode(w io.Writer, e *yang.Entry, nest bool) {
 	fmt.Fprintln(w, "}")
 }
 
+// hasPresence returns true if the field for the leaf e should track
+// presence in proto3: e is not a key, not mandatory and has no default, so
+// an unset leaf must be told apart from one set to the zero value.
+func hasPresence(e *yang.Entry) bool {
+	return e.Kind == yang.LeafEntry && e.ListAttr == nil && !isKey(e) && !isMandatory(e) && e.DefaultValue() == ""
+}
+
+// fieldComment returns a trailing comment annotating the field for e with
+// its effective range and length, if restricted, the paths it refers to
+// if it is a leafref, whether a reference need not refer to an existing
+// instance, the defaults of a leaf-list and the element count limits of a
+// list or leaf-list.  It returns "" if there is nothing to annotate.
+func fieldComment(e *yang.Entry) string {
+	var notes []string
+	if t := fieldType(e); t != nil {
+		if len(t.Range) > 0 {
+			if base := yang.BaseTypedefs[t.Kind.String()]; base == nil || !t.Range.Equal(base.YangType.Range) {
+				notes = append(notes, "range="+t.Range.String())
+			}
+		}
+		if len(t.Length) > 0 {
+			notes = append(notes, "length="+t.Length.String())
+		}
+	}
+	if e.Type != nil {
+		for _, p := range leafrefPaths(e, e.Type) {
+			notes = append(notes, "leafref="+p)
+		}
+	}
+	if t := e.Type; t != nil && t.OptionalInstance && (t.Kind == yang.Yleafref || t.Kind == yang.YinstanceIdentifier) {
+		notes = append(notes, "require-instance=false")
+	}
+	if e.ListAttr != nil && len(e.Defaults) > 0 {
+		notes = append(notes, fmt.Sprintf("defaults=[%s]", strings.Join(e.Defaults, ",")))
+	}
+	if la := e.ListAttr; la != nil {
+		if la.MinElements != nil {
+			notes = append(notes, "min-elements="+la.MinElements.Name)
+		}
+		if la.MaxElements != nil && la.MaxElements.Name != "unbounded" {
+			notes = append(notes, "max-elements="+la.MaxElements.Name)
+		}
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " // " + strings.Join(notes, " ")
+}
+
 // unionTypes returns a slice of all types in the union (and sub unions).
-func (pf *protofile) unionTypes(ut *yang.YangType, seen map[string]bool) []string {
+func (pf *protofile) unionTypes(se *yang.Entry, ut *yang.YangType, seen map[string]bool) []string {
 	var types []string
 	for _, t := range ut.Type {
 		k := t.Kind
@@ -557,11 +821,11 @@ func (pf *protofile) unionTypes(ut *yang.YangType, seen map[string]bool) []strin
 			k = yang.Yuint64
 		case yang.Yunion:
 			for _, st := range t.Type {
-				types = append(types, pf.unionTypes(st, seen)...)
+				types = append(types, pf.unionTypes(se, st, seen)...)
 			}
 			continue
 		}
-		kn := kind2proto[k]
+		kn, _ := lookupKind(kind2proto, "bytes", se, k)
 		if k == yang.Ydecimal64 {
 			kn = "Decimal64"
 			pf.hasDecimal64 = true
@@ -578,10 +842,13 @@ func (pf *protofile) unionTypes(ut *yang.YangType, seen map[string]bool) []strin
 
 // messageName returns the name for the message defined by e.
 func (pf *protofile) messageName(e *yang.Entry) string {
+	if qualifiedNames {
+		return pf.limitName(pf.qualifiedName(e))
+	}
 	if protoFlat {
 		return pf.fullName(e)
 	}
-	return pf.fixName(e.Name)
+	return pf.limitName(pf.fixName(e.Name))
 }
 
 // isPlural returns true if p is the plural of s.
@@ -593,10 +860,12 @@ func isPlural(s, p string) bool {
 	return s == p[:n-1] && p[n-1] == 's'
 }
 
-// fullName always returns the full pathname of entry e.
+// fullName always returns the full pathname of entry e, less any
+// --trim-prefix.
 func (pf *protofile) fullName(e *yang.Entry) string {
 	parts := []string{pf.fixName(e.Name)}
-	for p := e.Parent; p != nil && p.Parent != nil; p = p.Parent {
+	stop := trimmedAncestor(e)
+	for p := e.Parent; p != nil && p.Parent != nil && p != stop; p = p.Parent {
 		parts = append(parts, pf.fixName(p.Name))
 		// Don't output Foos_Foo_, just output Foo_
 		if len(p.Parent.Dir) == 1 && isPlural(p.Name, p.Parent.Name) {
@@ -606,39 +875,88 @@ func (pf *protofile) fullName(e *yang.Entry) string {
 	for i := 0; i < len(parts)/2; i++ {
 		parts[i], parts[len(parts)-i-1] = parts[len(parts)-i-1], parts[i]
 	}
-	return strings.Join(parts, "_")
+	return pf.limitName(strings.Join(parts, "_"))
 }
 
-// fieldName simply changes -'s to _'s.
+// qualifiedName returns the camel cased schema path of e, without the module
+// or any --trim-prefix, as a single name.  Unlike fullName, no path elements
+// are elided, so distinct entries have distinct names.
+func (pf *protofile) qualifiedName(e *yang.Entry) string {
+	var parts []string
+	stop := trimmedAncestor(e)
+	for p := e; p != nil && (p == e || p.Parent != nil) && p != stop; p = p.Parent {
+		parts = append(parts, pf.fixName(p.Name))
+	}
+	for i := 0; i < len(parts)/2; i++ {
+		parts[i], parts[len(parts)-i-1] = parts[len(parts)-i-1], parts[i]
+	}
+	return strings.Join(parts, "")
+}
+
+// fieldName changes -'s, and any other runes not allowed in an identifier,
+// to _'s.
 func (pf *protofile) fieldName(s string) string {
 	if s == "" {
 		return ""
 	}
-	fn := strings.Replace(s, "-", "_", -1)
+	fn := yang.SanitizeIdentifier(s)
 	switch {
 	case fn[0] >= 'a' && fn[0] <= 'z':
 	case fn[0] >= 'A' && fn[0] <= 'Z':
 	default:
 		fn = "X_" + fn
 	}
-	if fn != s {
-		if o := pf.fixedNames[fn]; o != "" && o != s {
-			pf.errs = append(pf.errs, fmt.Errorf("collision on %s and %s\n", o, s))
-		}
-		pf.fixedNames[fn] = s
-	}
+	pf.checkCollision(fn, s)
 	return fn
 }
 
+// memberName returns the name of the enum member s as used in a generated
+// constant.  The name is upper cased unless --keep-case is set.
+func (pf *protofile) memberName(s string) string {
+	if keepCase {
+		return pf.fieldName(s)
+	}
+	return strings.ToUpper(pf.fieldName(s))
+}
+
+// enumMemberName returns memberName(s) for a member of the enum kind
+// declared by n.  seen maps the names already written for the enum to the
+// members they are from, and an error is reported if s collides with one of
+// them.  Unless --case-sensitive-dedup is set, names differing only in case
+// collide.
+func (pf *protofile) enumMemberName(n yang.Node, kind string, seen map[string]string, s string) string {
+	name := pf.memberName(s)
+	key := name
+	if !caseSensitiveDedup {
+		key = strings.ToUpper(name)
+	}
+	if o, ok := seen[key]; ok && o != s {
+		reportError(fmt.Errorf("%s: members %s and %s of %s are both written as %s", yang.Source(n), o, s, kind, name))
+	}
+	seen[key] = s
+	return name
+}
+
+// checkCollision records that s was fixed to the name fn, and records an
+// error if a different name was already fixed to fn.  Unless
+// --case-sensitive-dedup is set, names differing only in case collide.
+func (pf *protofile) checkCollision(fn, s string) {
+	if fn == s && caseSensitiveDedup {
+		return
+	}
+	if !caseSensitiveDedup {
+		fn = strings.ToLower(fn)
+	}
+	if o := pf.fixedNames[fn]; o != "" && o != s {
+		pf.errs = append(pf.errs, fmt.Errorf("collision on %s and %s\n", o, s))
+	}
+	pf.fixedNames[fn] = s
+}
+
 // fixName returns s in camel case
 func (pf *protofile) fixName(s string) string {
 	cc := yang.CamelCase(s)
-	if cc != s {
-		if o := pf.fixedNames[cc]; o != "" && o != s {
-			pf.errs = append(pf.errs, fmt.Errorf("collision on %s and %s\n", o, s))
-		}
-		pf.fixedNames[cc] = s
-	}
+	pf.checkCollision(cc, s)
 	return cc
 }
 
@@ -648,17 +966,42 @@ func flatten(e *yang.Entry) []*yang.Entry {
 		return nil
 	}
 	f := []*yang.Entry{e}
-	var names []string
-	for n := range e.Dir {
-		names = append(names, n)
-	}
-	sort.Strings(names)
-	for _, n := range names {
-		f = append(f, flatten(e.Dir[n])...)
+	for _, se := range nameSortedDir(e) {
+		f = append(f, flatten(se)...)
 	}
 	return f
 }
 
+// protoEnumNames returns the names of e in the order they were declared,
+// except that the first member with the value 0 is moved to the front.
+func protoEnumNames(e *yang.EnumType) []string {
+	names := e.DeclaredNames()
+	for i, n := range names {
+		if e.Value(n) == 0 {
+			copy(names[1:i+1], names[:i])
+			names[0] = n
+			break
+		}
+	}
+	return names
+}
+
+// hasAliases returns true if two members of e have the same value.
+func hasAliases(e *yang.EnumType) bool {
+	return len(dedup(e.Values())) != len(e.Values())
+}
+
+// enumKey returns a key identifying the members of the enumeration st,
+// their names and values, so identical enums have the same key.
+func enumKey(st *yang.YangType) string {
+	var parts []string
+	for n, v := range st.Enum.NameMap() {
+		parts = append(parts, fmt.Sprintf("%s=%d", n, v))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
 // dedump returns the sorted slice a with duplicate values removed.
 func dedup(a []int64) []int64 {
 	if len(a) == 0 {
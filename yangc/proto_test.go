@@ -0,0 +1,1039 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestLeafListDefaults(t *testing.T) {
+	e := compileString(t, "defaults.yang", `
+module defaults {
+  yang-version 1.1;
+  namespace "urn:defaults";
+  prefix "d";
+
+  container servers {
+    leaf-list name {
+      type string;
+      default "alpha";
+      default "beta";
+    }
+  }
+}
+`)[0]
+	if got, want := e.Dir["servers"].Dir["name"].Defaults, []string{"alpha", "beta"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got defaults %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	doProto(&buf, []*yang.Entry{e})
+	if want := " // defaults=[alpha,beta]"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestWithReferences(t *testing.T) {
+	defer func(b bool) { withReferences = b }(withReferences)
+
+	e := compileString(t, "refs.yang", `
+module refs {
+  namespace "urn:refs";
+  prefix "r";
+
+  container system {
+    leaf hostname {
+      type string;
+      description "The host name.";
+      reference "RFC 1123: Requirements for Internet Hosts";
+    }
+  }
+}
+`)[0]
+	const want = "  // reference: RFC 1123: Requirements for Internet Hosts\n"
+	for _, with := range []bool{false, true} {
+		withReferences = with
+		var buf bytes.Buffer
+		doProto(&buf, []*yang.Entry{e})
+		if got := strings.Contains(buf.String(), want); got != with {
+			t.Errorf("with-references=%v: reference emitted %v, want %v:\n%s", with, got, with, buf.String())
+		}
+	}
+}
+
+func TestMultibyteEnumName(t *testing.T) {
+	e := compileString(t, "utf8.yang", `
+module utf8 {
+  namespace "urn:utf8";
+  prefix "u";
+
+  container menu {
+    leaf dish {
+      type enumeration {
+        enum "café";
+        enum "crème-brûlée";
+      }
+    }
+  }
+}
+`)[0]
+	var buf bytes.Buffer
+	doProto(&buf, []*yang.Entry{e})
+	for _, want := range []string{"  Dish_CAF_ = ", "  Dish_CR_ME_BR_L_E = "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		for _, r := range line {
+			if r >= utf8.RuneSelf {
+				t.Errorf("non-ASCII rune %q in %q", r, line)
+			}
+		}
+	}
+}
+
+func TestListOfUnions(t *testing.T) {
+	e := compileString(t, "list-union.yang", `
+module list-union {
+  namespace "urn:list-union";
+  prefix "lu";
+
+  container host {
+    leaf-list addr {
+      type union {
+        type uint32;
+        type string;
+      }
+    }
+  }
+}
+`)[0]
+	var buf bytes.Buffer
+	doProto(&buf, []*yang.Entry{e})
+	for _, want := range []string{
+		"\n  message Addr {\n    oneof Addr {\n      string Addr_string = 1;\n      uint32 Addr_uint32 = 2;\n    }\n  }\n",
+		"\n  repeated Addr addr = 1;\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestQualifiedNames(t *testing.T) {
+	defer func(b bool) { qualifiedNames = b }(qualifiedNames)
+
+	e := compileString(t, "qualified.yang", `
+module qualified {
+  namespace "urn:qualified";
+  prefix "q";
+
+  container interfaces {
+    list interface {
+      key name;
+      leaf name { type string; }
+      list address {
+        key ip;
+        leaf ip { type string; }
+      }
+    }
+  }
+  container servers {
+    list server {
+      key name;
+      leaf name { type string; }
+      list address {
+        key ip;
+        leaf ip { type string; }
+      }
+    }
+  }
+}
+`)[0]
+	for _, tt := range []struct {
+		qualified bool
+		want      []string
+	}{
+		{false, []string{"message Address {"}},
+		{true, []string{"message InterfacesInterfaceAddress {", "message ServersServerAddress {"}},
+	} {
+		qualifiedNames = tt.qualified
+		var buf bytes.Buffer
+		doProto(&buf, []*yang.Entry{e})
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("qualified-names=%v: missing %q in output:\n%s", tt.qualified, want, buf.String())
+			}
+		}
+	}
+}
+
+// largeModule returns a module with n containers of 20 leaves each.
+func largeModule(n int) string {
+	var b strings.Builder
+	b.WriteString("module large {\n  namespace \"urn:large\";\n  prefix \"l\";\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "  container c%d {\n", i)
+		for j := 0; j < 20; j++ {
+			fmt.Fprintf(&b, "    leaf l%d { type uint32; description \"leaf %d of container %d\"; }\n", j, j, i)
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// countingWriter counts the bytes and calls written to it.
+type countingWriter struct {
+	n, writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	w.writes++
+	return len(p), nil
+}
+
+func TestProtoStreams(t *testing.T) {
+	e := compileString(t, "large.yang", largeModule(100))[0]
+	var cw countingWriter
+	doProto(&cw, []*yang.Entry{e})
+	// The output is written as it is generated rather than in a single
+	// write at the end.
+	if cw.writes < 2 {
+		t.Errorf("%d bytes written in %d writes, want more than 1", cw.n, cw.writes)
+	}
+}
+
+func BenchmarkProto(b *testing.B) {
+	ms := yang.NewModules()
+	if err := ms.Parse(largeModule(1000), "large.yang"); err != nil {
+		b.Fatal(err)
+	}
+	entries, errs := moduleEntries(ms)
+	if len(errs) > 0 {
+		b.Fatal(errs)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doProto(ioutil.Discard, entries)
+	}
+}
+
+// TestProtoAllocsGrowth checks that the allocations made generating proto
+// grow no faster than the model, so the output is not held in memory.
+func TestProtoAllocsGrowth(t *testing.T) {
+	perContainer := map[int]float64{}
+	for _, n := range []int{50, 500} {
+		ms := yang.NewModules()
+		if err := ms.Parse(largeModule(n), "large.yang"); err != nil {
+			t.Fatal(err)
+		}
+		entries, errs := moduleEntries(ms)
+		if len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		allocs := testing.AllocsPerRun(2, func() {
+			doProto(ioutil.Discard, entries)
+		})
+		perContainer[n] = allocs / float64(n)
+	}
+	if small, large := perContainer[50], perContainer[500]; large > 1.5*small {
+		t.Errorf("allocations per container grew from %.0f to %.0f as the model grew tenfold", small, large)
+	}
+}
+
+// wideModule returns a module with a single container of n leaves.
+func wideModule(n int) string {
+	var b strings.Builder
+	b.WriteString("module wide {\n  namespace \"urn:wide\";\n  prefix \"w\";\n  container c {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "    leaf l%d { type uint32; }\n", i)
+	}
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
+
+// BenchmarkProtoWide generates a message with thousands of fields.  The
+// time per field should stay about the same as the message grows.
+func BenchmarkProtoWide(b *testing.B) {
+	defer func(b bool) { stableTags = b }(stableTags)
+	for _, n := range []int{1000, 10000} {
+		ms := yang.NewModules()
+		if err := ms.Parse(wideModule(n), "wide.yang"); err != nil {
+			b.Fatal(err)
+		}
+		entries, errs := moduleEntries(ms)
+		if len(errs) > 0 {
+			b.Fatal(errs)
+		}
+		for _, stable := range []bool{false, true} {
+			b.Run(fmt.Sprintf("fields=%d/stable=%v", n, stable), func(b *testing.B) {
+				stableTags = stable
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					doProto(ioutil.Discard, entries)
+				}
+			})
+		}
+	}
+}
+
+func TestEffectiveRange(t *testing.T) {
+	e := compileString(t, "effective.yang", `
+module effective {
+  namespace "urn:effective";
+  prefix "e";
+
+  typedef base {
+    type uint8 { range "1..10"; }
+  }
+  typedef derived {
+    type base { range "2..8"; }
+  }
+
+  container c {
+    leaf plain { type uint8; }
+    leaf narrow { type base; }
+    leaf narrower { type derived; }
+  }
+}
+`)[0]
+	if got, want := e.Dir["c"].Dir["narrower"].Type.Range.String(), "2..8"; got != want {
+		t.Errorf("effective range %s, want %s", got, want)
+	}
+
+	var buf bytes.Buffer
+	doProto(&buf, []*yang.Entry{e})
+	for _, want := range []string{
+		"  uint32 plain = ",
+		"  uint32 narrow = ",
+		" // range=1..10\n",
+		" // range=2..8\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "range=0..255") {
+		t.Errorf("unrestricted uint8 annotated with its range:\n%s", buf.String())
+	}
+}
+
+func TestEffectiveLength(t *testing.T) {
+	e := compileString(t, "length.yang", `
+module length {
+  namespace "urn:length";
+  prefix "l";
+
+  typedef name {
+    type string { length "1..255"; }
+  }
+  typedef short-name {
+    type name { length "1..64"; }
+  }
+
+  container c {
+    leaf any { type string; }
+    leaf label { type short-name; }
+  }
+}
+`)[0]
+	if got, want := e.Dir["c"].Dir["label"].Type.Length.String(), "1..64"; got != want {
+		t.Errorf("effective length %s, want %s", got, want)
+	}
+
+	var buf bytes.Buffer
+	doProto(&buf, []*yang.Entry{e})
+	if want := "  string label = 2; // length=1..64\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+	if want := "  string any = 1;\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestStableTags(t *testing.T) {
+	defer func(b bool) { stableTags = b }(stableTags)
+	stableTags = true
+
+	tags := func(src string) map[string]string {
+		var buf bytes.Buffer
+		doProto(&buf, compileString(t, "stable.yang", src))
+		m := map[string]string{}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			f := strings.Fields(line)
+			if len(f) >= 4 && f[2] == "=" {
+				m[f[1]] = strings.TrimSuffix(f[3], ";")
+			}
+		}
+		return m
+	}
+	before := tags(`
+module stable {
+  namespace "urn:stable";
+  prefix "s";
+
+  container system {
+    leaf host-name { type string; }
+    leaf domain { type string; }
+  }
+}
+`)
+	after := tags(`
+module stable {
+  namespace "urn:stable";
+  prefix "s";
+
+  container system {
+    leaf contact { type string; }
+    leaf host-name { type string; }
+    leaf domain { type string; }
+  }
+}
+`)
+	if len(before) != 2 || len(after) != 3 {
+		t.Fatalf("got fields %v and %v, want 2 and 3", before, after)
+	}
+	for name, tag := range before {
+		if after[name] != tag {
+			t.Errorf("%s: tag changed from %s to %s", name, tag, after[name])
+		}
+	}
+}
+
+func TestStableTagCollision(t *testing.T) {
+	mi := &messageInfo{fields: map[string]int{}, used: map[int]bool{}, stable: true}
+	a := mi.tag("a", "string", false)
+	b := mi.tag("a", "int32", false)
+	if a == b {
+		t.Errorf("colliding fields both got tag %d", a)
+	}
+	if got := mi.tag("a", "string", false); got != a {
+		t.Errorf("tag changed from %d to %d", a, got)
+	}
+}
+
+func TestRPCNestedMessages(t *testing.T) {
+	entries := compileString(t, "rpc.yang", `
+module rpc {
+  yang-version 1.1;
+  namespace "urn:rpc";
+  prefix "r";
+
+  rpc configure {
+    input {
+      container options {
+        leaf force { type boolean; }
+      }
+      anydata payload;
+    }
+    output {
+      anyxml detail;
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	for _, want := range []string{
+		"message ConfigureRequest {\n  message Options {\n    bool force = 1;\n  }\n  Options options = 1;\n",
+		"  message Payload {\n  }\n  Payload payload = 2;\n}\n",
+		"message ConfigureResponse {\n  message Detail {\n  }\n  Detail detail = 1;\n}\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestCaseSensitiveDedup(t *testing.T) {
+	defer func(b, k bool) { caseSensitiveDedup, keepCase = b, k }(caseSensitiveDedup, keepCase)
+	defer func(c map[string]int, w io.Writer) { diagnosticCounts, diagOut = c, w }(diagnosticCounts, diagOut)
+
+	entries := compileString(t, "dedup.yang", `
+module dedup {
+  namespace "urn:dedup";
+  prefix "d";
+
+  container link {
+    leaf state {
+      type enumeration {
+        enum Up;
+        enum UP;
+      }
+    }
+    leaf admin {
+      type enumeration {
+        enum up;
+      }
+    }
+  }
+}
+`)
+	for _, tt := range []struct {
+		keepCase, sensitive bool
+		collide             bool
+	}{
+		// Upper cased, Up and UP are both written as UP.
+		{false, true, true},
+		{false, false, true},
+		{true, true, false},
+		{true, false, true},
+	} {
+		keepCase, caseSensitiveDedup = tt.keepCase, tt.sensitive
+		for name, gen := range map[string]func(io.Writer, []*yang.Entry){"proto": doProto, "header": doHeader} {
+			var out bytes.Buffer
+			diagOut = &out
+			diagnosticCounts = map[string]int{}
+			gen(ioutil.Discard, entries)
+			want := 0
+			if tt.collide {
+				want = 1
+			}
+			// The admin enum has its own members.
+			if got := strings.Count(out.String(), "are both written as"); got != want {
+				t.Errorf("%s keep case %v, case sensitive %v: got %d collisions, want %d:\n%s", name, tt.keepCase, tt.sensitive, got, want, out.String())
+			}
+		}
+	}
+}
+
+func TestLeafListEnum(t *testing.T) {
+	entries := compileString(t, "modes.yang", `
+module modes {
+  namespace "urn:modes";
+  prefix "m";
+
+  container port {
+    leaf-list mode {
+      type enumeration {
+        enum access;
+        enum trunk;
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	out := buf.String()
+	want := `message Port {
+  enum Mode {
+    Mode_ACCESS = 0;
+    Mode_TRUNK = 1;
+  };
+  repeated Mode mode = 1;
+}
+`
+	if !strings.Contains(out, want) {
+		t.Errorf("missing %q in output:\n%s", want, out)
+	}
+	if n := strings.Count(out, "enum Mode {"); n != 1 {
+		t.Errorf("enum Mode defined %d times:\n%s", n, out)
+	}
+}
+
+func TestKeepCase(t *testing.T) {
+	defer func(b bool) { keepCase = b }(keepCase)
+
+	entries := compileString(t, "case.yang", `
+module case {
+  namespace "urn:case";
+  prefix "c";
+
+  container link {
+    leaf speed {
+      type enumeration {
+        enum tenGig;
+        enum Auto;
+      }
+    }
+  }
+}
+`)
+	for _, tt := range []struct {
+		keep bool
+		want []string
+	}{
+		{false, []string{"Speed_TENGIG = ", "Speed_AUTO = "}},
+		{true, []string{"Speed_tenGig = ", "Speed_Auto = "}},
+	} {
+		keepCase = tt.keep
+		for _, gen := range []func(io.Writer, []*yang.Entry){doProto, doHeader} {
+			var buf bytes.Buffer
+			gen(&buf, entries)
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("keep case %v: missing %q in output:\n%s", tt.keep, want, buf.String())
+				}
+			}
+		}
+	}
+}
+
+func TestEnumUnset(t *testing.T) {
+	defer func(b bool) { enumUnset = b }(enumUnset)
+	enumUnset = true
+
+	entries := compileString(t, "unset.yang", `
+module unset {
+  namespace "urn:unset";
+  prefix "u";
+
+  container link {
+    leaf state {
+      type enumeration {
+        enum up { value 1; }
+        enum down { value 2; }
+      }
+    }
+    leaf mode {
+      type enumeration {
+        enum off { value 0; }
+        enum on { value 1; }
+      }
+    }
+    leaf level {
+      type enumeration {
+        enum a { value 5; }
+        enum b { value 0; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	out := buf.String()
+	for _, want := range []string{
+		"State_UNSET = 0;\n    State_UP = 1;\n    State_DOWN = 2;\n",
+		"Mode_OFF = 0;\n    Mode_ON = 1;\n",
+		"Level_B = 0;\n    Level_A = 5;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+	for _, unset := range []string{"Mode_UNSET", "Level_UNSET"} {
+		if strings.Contains(out, unset) {
+			t.Errorf("%s added to enum with a zero value:\n%s", unset, out)
+		}
+	}
+}
+
+func TestProtoEnumValues(t *testing.T) {
+	defer func(b bool) { yang.ParseOptions.AllowEnumAliases = b }(yang.ParseOptions.AllowEnumAliases)
+	yang.ParseOptions.AllowEnumAliases = true
+
+	entries := compileString(t, "values.yang", `
+module values {
+  namespace "urn:values";
+  prefix "v";
+
+  container paint {
+    leaf color {
+      type enumeration {
+        enum red;
+        enum blue { value 2; }
+        enum crimson { value 0; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	want := `  enum Color {
+    option allow_alias = true;
+    Color_RED = 0;
+    Color_BLUE = 2;
+    Color_CRIMSON = 0;
+  };
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestMergeEnums(t *testing.T) {
+	defer func(b bool) { mergeEnums = b }(mergeEnums)
+	mergeEnums = true
+
+	entries := compileString(t, "merge.yang", `
+module merge {
+  namespace "urn:merge";
+  prefix "m";
+
+  container port {
+    leaf admin-status {
+      type enumeration {
+        enum up;
+        enum down;
+      }
+    }
+    leaf oper-status {
+      type enumeration {
+        enum up;
+        enum down;
+      }
+    }
+    leaf speed {
+      type enumeration {
+        enum up;
+        enum down { value 2; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	out := buf.String()
+	for _, want := range []string{
+		"enum AdminStatus {",
+		"AdminStatus admin_status = 1;",
+		"AdminStatus oper_status = 2;",
+		"enum Speed {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "OperStatus") {
+		t.Errorf("identical enum written twice:\n%s", out)
+	}
+}
+
+func TestOneofTags(t *testing.T) {
+	e := compileString(t, "oneof.yang", `
+module oneof {
+  namespace "urn:oneof";
+  prefix "o";
+
+  container peer {
+    leaf address {
+      type union {
+        type int32;
+        type string;
+        type boolean;
+      }
+    }
+    leaf port { type uint16; }
+  }
+}
+`)[0]
+	pf := &protofile{
+		fixedNames: map[string]string{},
+		messages:   map[string]*messageInfo{},
+	}
+	var buf bytes.Buffer
+	pf.printProto(&buf, e)
+	tags := map[int]string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		var kind, name string
+		var tag int
+		if n, _ := fmt.Sscanf(strings.TrimSpace(line), "%s %s = %d;", &kind, &name, &tag); n != 3 {
+			continue
+		}
+		if o, ok := tags[tag]; ok {
+			t.Errorf("%s and %s both have tag %d", o, name, tag)
+		}
+		tags[tag] = name
+	}
+	if len(tags) != 4 {
+		t.Errorf("got fields %v, want 4 distinct tags:\n%s", tags, buf.String())
+	}
+}
+
+func TestOptionalPresence(t *testing.T) {
+	defer func(b bool) { proto3Optional = b }(proto3Optional)
+	proto3Optional = true
+
+	entries := compileString(t, "presence.yang", `
+module presence {
+  namespace "urn:presence";
+  prefix "p";
+
+  list server {
+    key name;
+    leaf name { type string; }
+    leaf host { type string; mandatory true; }
+    leaf port { type uint16; default 22; }
+    leaf banner { type string; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	out := buf.String()
+	for _, want := range []string{
+		"\n  string name = 1;",
+		"\n  string host = 2;",
+		"\n  uint32 port = 3;",
+		"\n  optional string banner = 4;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestUnknownKind(t *testing.T) {
+	defer func(c map[string]int, w io.Writer, mode string) {
+		diagnosticCounts, diagOut, onUnknownType = c, w, mode
+	}(diagnosticCounts, diagOut, onUnknownType)
+
+	entries := compileString(t, "unknown.yang", `
+module unknown {
+  namespace "urn:unknown";
+  prefix "u";
+
+  container system {
+    leaf name { type string; }
+  }
+}
+`)
+	// Force the kind of a partially resolved entry.
+	entries[0].Dir["system"].Dir["name"].Type.Kind = yang.Ynone
+
+	for _, tt := range []struct {
+		mode     string
+		comment  bool
+		severity string
+	}{
+		{"error", true, "error"},
+		{"warn", true, "warning"},
+		{"bytes", false, ""},
+	} {
+		onUnknownType = tt.mode
+		for _, g := range []struct {
+			gen     func(io.Writer, []*yang.Entry)
+			field   string
+			comment string // written when reported, if any
+		}{
+			{doProto, "  bytes name = 1;", "// *WARNING* unknown type kind none\n"},
+			{doHeader, "\nchar * name = 1;", "// *WARNING* unknown type kind none\n"},
+			{doCapnp, "name @0 :Data;", "# *WARNING* unknown type kind none\n"},
+			{doTypeScript, "name?: unknown;", ""},
+		} {
+			var diags bytes.Buffer
+			diagOut = &diags
+			diagnosticCounts = map[string]int{}
+			var buf bytes.Buffer
+			g.gen(&buf, entries)
+			out := buf.String()
+			if !strings.Contains(out, g.field) {
+				t.Errorf("%s: missing %q in output:\n%s", tt.mode, g.field, out)
+			}
+			if g.comment != "" && strings.Contains(out, g.comment) != tt.comment {
+				t.Errorf("%s: got comment %v, want %v:\n%s", tt.mode, !tt.comment, tt.comment, out)
+			}
+			want := map[string]int{}
+			if tt.severity != "" {
+				want[tt.severity] = 1
+			}
+			if !reflect.DeepEqual(diagnosticCounts, want) {
+				t.Errorf("%s: got diagnostics %v, want %v: %s", tt.mode, diagnosticCounts, want, diags.String())
+			}
+		}
+	}
+
+	onUnknownType = "bogus"
+	if err := checkOnUnknownType(); err == nil {
+		t.Error("invalid --on-unknown-type accepted")
+	}
+}
+
+func TestUnknownUnionMemberKind(t *testing.T) {
+	defer func(c map[string]int, w io.Writer, mode string) {
+		diagnosticCounts, diagOut, onUnknownType = c, w, mode
+	}(diagnosticCounts, diagOut, onUnknownType)
+	onUnknownType = "warn"
+	diagnosticCounts = map[string]int{}
+	var diags bytes.Buffer
+	diagOut = &diags
+
+	entries := compileString(t, "member.yang", `
+module member {
+  namespace "urn:member";
+  prefix "m";
+
+  container peer {
+    leaf address { type union { type int32; type string; } }
+  }
+}
+`)
+	// Force the kind of a partially resolved union member.
+	entries[0].Dir["peer"].Dir["address"].Type.Type[1].Kind = yang.Ynone
+
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	if want := "bytes Address_bytes = "; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+	if n := diagnosticCounts["warning"]; n != 1 {
+		t.Errorf("got %d warnings, want 1: %s", n, diags.String())
+	}
+}
+
+func TestRequireInstanceFalse(t *testing.T) {
+	entries := compileString(t, "ref.yang", `
+module ref {
+  yang-version 1.1;
+  namespace "urn:ref";
+  prefix "r";
+
+  container system {
+    leaf name { type string; }
+    leaf optional {
+      type leafref {
+        path "../name";
+        require-instance false;
+      }
+    }
+    leaf required {
+      type leafref { path "../name"; }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	out := buf.String()
+	if want := "string optional = 2; // leafref=/ref:system/name require-instance=false\n"; !strings.Contains(out, want) {
+		t.Errorf("missing %q in output:\n%s", want, out)
+	}
+	if want := "string required = 3; // leafref=/ref:system/name\n"; !strings.Contains(out, want) {
+		t.Errorf("missing %q in output:\n%s", want, out)
+	}
+}
+
+func TestBitsPositions(t *testing.T) {
+	entries := compileString(t, "bits.yang", `
+module bits {
+  namespace "urn:bits";
+  prefix "b";
+
+  container port {
+    leaf flags {
+      type bits {
+        bit bar { position 0; }
+        bit foo { position 5; }
+        bit baz;
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	for _, want := range []string{
+		"    Flags_BAR = 1;\n",
+		"    Flags_FOO = 32;\n",
+		"    Flags_BAZ = 64;\n", // follows the highest position
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestNestedScopeTags(t *testing.T) {
+	entries := compileString(t, "scopes.yang", `
+module scopes {
+  namespace "urn:scopes";
+  prefix "s";
+
+  container top {
+    leaf name { type string; }
+    container inner {
+      leaf name { type string; }
+      leaf zeta { type string; }
+      container inner {
+        leaf name { type string; }
+        leaf alpha { type int32; }
+      }
+    }
+    leaf zeta { type int32; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	// Each message numbers its own fields from 1, whatever the fields of
+	// the messages enclosing it or nested in it are named.
+	for _, want := range []string{
+		"// goyang-tag Top name/string 1\n",
+		"// goyang-tag Top inner/Inner 2\n",
+		"// goyang-tag Top zeta/int32 3\n",
+		"// goyang-tag Top_Inner name/string 1\n",
+		"// goyang-tag Top_Inner zeta/string 2\n",
+		"// goyang-tag Top_Inner inner/Inner 3\n",
+		"// goyang-tag Top_Inner_Inner name/string 1\n",
+		"// goyang-tag Top_Inner_Inner alpha/int32 2\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestElementCounts(t *testing.T) {
+	entries := compileString(t, "counts.yang", `
+module counts {
+  namespace "urn:counts";
+  prefix "c";
+
+  container dns {
+    list server {
+      key "address";
+      min-elements 1;
+      max-elements 10;
+      leaf address { type string; }
+    }
+    leaf-list search { type string; max-elements unbounded; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doProto(&buf, entries)
+	out := buf.String()
+	if want := "repeated Server server = 1; // min-elements=1 max-elements=10\n"; !strings.Contains(out, want) {
+		t.Errorf("missing %q in output:\n%s", want, out)
+	}
+	if want := "repeated string search = 2;\n"; !strings.Contains(out, want) {
+		t.Errorf("missing %q in output:\n%s", want, out)
+	}
+}
+
+func TestBannerNamespace(t *testing.T) {
+	entries := compileString(t, "ns.yang", `module ns { namespace "urn:example:ns"; prefix "n"; container c { leaf l { type string; } } }`)
+	if got := yang.ModuleNamespace(entries[0]); got != "urn:example:ns" {
+		t.Errorf("got namespace %q, want urn:example:ns", got)
+	}
+	for _, gen := range []func(io.Writer, []*yang.Entry){doProto, doHeader} {
+		var buf bytes.Buffer
+		gen(&buf, entries)
+		if want := "// namespace \"urn:example:ns\"\n"; !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+	// The Cap'n Proto file ID is derived from the namespace, not the name.
+	other := compileString(t, "other.yang", `module other { namespace "urn:example:ns"; prefix "o"; }`)
+	if capnpID(entries[0]) != capnpID(other[0]) {
+		t.Errorf("modules with the same namespace have different capnp IDs")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/paranpen/yangc/pkg/indent"
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// withReferences adds the reference statement of a node to its comments.
+var withReferences bool
+
+func init() {
+	mainCmd.PersistentFlags().BoolVar(&withReferences, "with-references", false, "emit reference statements as comments")
+}
+
+// reference returns the text of the reference statement of the node e was
+// derived from, or "" if it has none.
+func reference(e *yang.Entry) string {
+	return nodeValue(e, "Reference")
+}
+
+// nodeValue returns the argument of the substatement stored in field of the
+// node e was derived from, or "" if the node has no such substatement.
+func nodeValue(e *yang.Entry, field string) string {
+	if e.Node == nil {
+		return ""
+	}
+	v := reflect.ValueOf(e.Node)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	f := v.Elem().FieldByName(field)
+	if !f.IsValid() {
+		return ""
+	}
+	if r, ok := f.Interface().(*yang.Value); ok && r != nil {
+		return r.Name
+	}
+	return ""
+}
+
+// writeReference writes the reference of e to w as a comment with each line
+// prefixed by prefix.  Nothing is written unless --with-references is set.
+func writeReference(w io.Writer, prefix string, e *yang.Entry) {
+	if !withReferences {
+		return
+	}
+	if r := reference(e); r != "" {
+		fmt.Fprintln(indent.NewWriter(w, prefix+"// "), "reference: "+r)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var restconfCmd = &cobra.Command{
+		Use:   "restconf-paths",
+		Short: "print the RESTCONF URI template of each data node",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doRESTCONFPaths(os.Stdout, entries)
+		},
+	}
+	mainCmd.AddCommand(restconfCmd)
+}
+
+// doRESTCONFPaths writes the RESTCONF URI template of every data node in
+// entries to w, one per line.  List keys are written as {key} placeholders,
+// as in /list={name},{type}.  The first node, and nodes augmented in from
+// other modules, are qualified with the name of their module.
+func doRESTCONFPaths(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		for _, se := range children(e) {
+			walkDataNodes(se, nil, func(path []*yang.Entry) {
+				fmt.Fprint(w, "/restconf/data")
+				for i, p := range path {
+					name := p.PathName()
+					if i == 0 {
+						// A top-level node in a choice is
+						// not a child of the module entry.
+						name = e.Name + ":" + p.Name
+					}
+					fmt.Fprintf(w, "/%s", name)
+					if keys := listKeys(p); len(keys) > 0 {
+						for i, k := range keys {
+							keys[i] = "{" + k + "}"
+						}
+						fmt.Fprintf(w, "=%s", strings.Join(keys, ","))
+					}
+				}
+				fmt.Fprintln(w)
+			})
+		}
+	}
+}
+
+// walkDataNodes calls f with the path to e, and then with the path to each
+// of its descendants, where the path to e is parents followed by e.  Choices
+// and cases are not part of the data tree and are left out of the paths.
+func walkDataNodes(e *yang.Entry, parents []*yang.Entry, f func(path []*yang.Entry)) {
+	path := parents
+	if !isChoice(e) {
+		path = append(parents[:len(parents):len(parents)], e)
+		f(path)
+	}
+	for _, se := range children(e) {
+		walkDataNodes(se, path, f)
+	}
+}
+
+// listKeys returns the names of the keys of e if e is a keyed list.
+func listKeys(e *yang.Entry) []string {
+	if e.ListAttr == nil {
+		return nil
+	}
+	return strings.Fields(e.Key)
+}
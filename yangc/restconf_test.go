@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRESTCONFPaths(t *testing.T) {
+	entries := compileString(t, "rc.yang", `
+module rc {
+  namespace "urn:rc";
+  prefix "rc";
+
+  container interfaces {
+    list interface {
+      key "name unit";
+      leaf name { type string; }
+      leaf unit { type uint32; }
+      choice mode {
+        leaf trunk { type empty; }
+        leaf access { type uint16; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doRESTCONFPaths(&buf, entries)
+	want := `/restconf/data/rc:interfaces
+/restconf/data/rc:interfaces/interface={name},{unit}
+/restconf/data/rc:interfaces/interface={name},{unit}/name
+/restconf/data/rc:interfaces/interface={name},{unit}/unit
+/restconf/data/rc:interfaces/interface={name},{unit}/trunk
+/restconf/data/rc:interfaces/interface={name},{unit}/access
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRESTCONFAugmentPaths(t *testing.T) {
+	entries := compileStrings(t, map[string]string{
+		"base": `
+module base {
+  namespace "urn:base";
+  prefix "b";
+
+  container system {
+    container clock { leaf timezone { type string; } }
+  }
+}
+`,
+		"extra": `
+module extra {
+  namespace "urn:extra";
+  prefix "x";
+  import base { prefix b; }
+
+  augment "/b:system/b:clock" {
+    container offset { leaf minutes { type int16; } }
+  }
+}
+`,
+	})
+	var buf bytes.Buffer
+	doRESTCONFPaths(&buf, entries)
+	for _, want := range []string{
+		"/restconf/data/base:system/clock/timezone\n",
+		"/restconf/data/base:system/clock/extra:offset\n",
+		"/restconf/data/base:system/clock/extra:offset/minutes\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// moduleRevisions pins modules to a revision, each as name@revision-date.
+var moduleRevisions []string
+
+func init() {
+	mainCmd.PersistentFlags().StringSliceVar(&moduleRevisions, "module-revision", nil, "comma separated list of name@revision-date pinning the revision of a module found on the path")
+}
+
+// readPinnedRevisions reads the pinned revision of each module in
+// --module-revision into ms.  A module read before its imports are resolved
+// is used in place of the latest revision on the path.  It is an error for
+// a pinned revision not to be found.
+func readPinnedRevisions(ms *yang.Modules) []error {
+	var errs []error
+	for _, pin := range moduleRevisions {
+		i := strings.Index(pin, "@")
+		if i <= 0 || i == len(pin)-1 {
+			errs = append(errs, fmt.Errorf("invalid --module-revision %q: want name@revision-date", pin))
+			continue
+		}
+		if err := ms.Read(pin); err != nil {
+			errs = append(errs, fmt.Errorf("module %s revision %s not found: %v", pin[:i], pin[i+1:], err))
+			continue
+		}
+		if ms.Modules[pin] == nil && ms.SubModules[pin] == nil {
+			errs = append(errs, fmt.Errorf("module %s has no revision %s", pin[:i], pin[i+1:]))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModuleRevision(t *testing.T) {
+	defer func(s []string) { moduleRevisions = s }(moduleRevisions)
+
+	dir, err := ioutil.TempDir("", "yangc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dep := func(rev, leaf string) string {
+		return `module dep {
+  namespace "urn:dep";
+  prefix "d";
+  revision ` + rev + `;
+  grouping g { leaf ` + leaf + ` { type string; } }
+}`
+	}
+	for name, src := range map[string]string{
+		"top.yang":            `module top { namespace "urn:top"; prefix "t"; import dep { prefix d; } container c { uses d:g; } }`,
+		"dep@2020-01-01.yang": dep("2020-01-01", "old-leaf"),
+		"dep@2022-01-01.yang": dep("2022-01-01", "new-leaf"),
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	top := filepath.Join(dir, "top.yang")
+
+	for _, tt := range []struct {
+		pin       []string
+		want, not string
+	}{
+		{[]string{"dep@2020-01-01"}, "old_leaf", "new_leaf"},
+		{[]string{"dep@2022-01-01"}, "new_leaf", "old_leaf"},
+	} {
+		moduleRevisions = tt.pin
+		entries, errs := compile(top)
+		if len(errs) > 0 {
+			t.Fatalf("%v: %v", tt.pin, errs)
+		}
+		var buf bytes.Buffer
+		doProto(&buf, entries)
+		if out := buf.String(); !strings.Contains(out, tt.want) || strings.Contains(out, tt.not) {
+			t.Errorf("%v: want %s and not %s in output:\n%s", tt.pin, tt.want, tt.not, out)
+		}
+	}
+
+	for _, pin := range []string{"dep@2021-01-01", "dep"} {
+		moduleRevisions = []string{pin}
+		if _, errs := compile(top); len(errs) == 0 {
+			t.Errorf("%s: no error", pin)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var sexpCmd = &cobra.Command{
+		Use:   "sexp",
+		Short: "print the statement tree of each module as S-expressions",
+		Run: func(cmd *cobra.Command, args []string) {
+			ms, errs := readModules(yangFileName)
+			exitIfError(errs)
+			doSexp(os.Stdout, ms)
+		},
+	}
+	mainCmd.AddCommand(sexpCmd)
+}
+
+// doSexp writes the statements of each module read into ms to w, one module
+// per line, as nested S-expressions of keyword and argument.  The
+// statements are as parsed, nothing is resolved.
+func doSexp(w io.Writer, ms *yang.Modules) {
+	for _, m := range parsedModules(ms) {
+		writeSexp(w, m.Statement())
+		fmt.Fprintln(w)
+	}
+}
+
+// writeSexp writes s and its substatements to w as an S-expression.
+func writeSexp(w io.Writer, s *yang.Statement) {
+	fmt.Fprintf(w, "(%s", s.Keyword())
+	if arg, ok := s.Arg(); ok {
+		fmt.Fprintf(w, " %s", sexpAtom(arg))
+	}
+	for _, ss := range s.SubStatements() {
+		fmt.Fprint(w, " ")
+		writeSexp(w, ss)
+	}
+	fmt.Fprint(w, ")")
+}
+
+// sexpAtom returns arg quoted if it would not read back as a single atom.
+func sexpAtom(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\n\"()") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
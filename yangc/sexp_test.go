@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestSexp(t *testing.T) {
+	ms := yang.NewModules()
+	if err := ms.Parse(`
+module small {
+  namespace "urn:small";
+  prefix "s";
+
+  container foo {
+    description "a (small) container";
+    leaf bar { type uint32; }
+  }
+}
+`, "small.yang"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	doSexp(&buf, ms)
+	want := `(module small (namespace urn:small) (prefix s) (container foo (description "a (small) container") (leaf bar (type uint32))))` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
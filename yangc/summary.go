@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// quiet suppresses the summary of the generated types.
+var quiet bool
+
+// generatedTypes and generatedModules count the top-level types, and the
+// modules they are from, written by the generators.
+var generatedTypes, generatedModules int
+
+func init() {
+	mainCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "do not report the number of generated types")
+}
+
+// countGenerated adds the types pf wrote for a module to the counts.
+func countGenerated(pf *protofile) {
+	generatedTypes += pf.types
+	generatedModules++
+}
+
+// writeGeneratedSummary writes the number of generated types and modules to
+// w, unless nothing was generated, --quiet is set or the diagnostics are
+// JSON, which the plain text line would break.
+func writeGeneratedSummary(w io.Writer) {
+	if quiet || generatedModules == 0 || errorFormat == "json" {
+		return
+	}
+	fmt.Fprintf(w, "yangc: generated %d %s from %d %s\n", generatedTypes, plural(generatedTypes, "type"), generatedModules, plural(generatedModules, "module"))
+}
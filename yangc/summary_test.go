@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestGeneratedSummary(t *testing.T) {
+	defer func(types, modules int) { generatedTypes, generatedModules = types, modules }(generatedTypes, generatedModules)
+	defer func(b bool) { quiet = b }(quiet)
+	defer func(s string) { errorFormat = s }(errorFormat)
+
+	entries := compileStrings(t, map[string]string{
+		"one": `
+module one {
+  namespace "urn:one";
+  prefix "o";
+
+  typedef percent { type uint8; }
+  container system {
+    container clock { leaf zone { type string; } }
+  }
+  list server { key name; leaf name { type string; } }
+}
+`,
+		"two": `module two { namespace "urn:two"; prefix "t"; container c { leaf l { type decimal64 { fraction-digits 2; } } } }`,
+	})
+	for _, tt := range []struct {
+		gen  func(io.Writer, []*yang.Entry)
+		want string
+	}{
+		// system, server and c, plus Decimal64 for two.
+		{doProto, "yangc: generated 4 types from 2 modules\n"},
+		// percent, system, server and c.
+		{doHeader, "yangc: generated 4 types from 2 modules\n"},
+	} {
+		generatedTypes, generatedModules = 0, 0
+		tt.gen(ioutil.Discard, entries)
+		var buf bytes.Buffer
+		writeGeneratedSummary(&buf)
+		if buf.String() != tt.want {
+			t.Errorf("got %q, want %q", buf.String(), tt.want)
+		}
+	}
+
+	quiet = true
+	var buf bytes.Buffer
+	writeGeneratedSummary(&buf)
+	if buf.Len() != 0 {
+		t.Errorf("quiet: got %q", buf.String())
+	}
+
+	quiet = false
+	errorFormat = "json"
+	writeGeneratedSummary(&buf)
+	if buf.Len() != 0 {
+		t.Errorf("--error-format json: got %q", buf.String())
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// tagMapFile, when set, is the file proto writes the tag of every leaf to,
+// as a JSON object keyed by the schema path of the leaf.  The tags of a union
+// written as a oneof are an object keyed by the type of each member.
+var tagMapFile string
+
+// recordTag records tag as the field tag of se, if se is a leaf and a tag
+// map is being collected.
+func (pf *protofile) recordTag(se *yang.Entry, tag int) {
+	if pf.tagMap != nil && se.Type != nil && len(se.Dir) == 0 {
+		pf.tagMap[se.FullPath()] = tag
+	}
+}
+
+// recordUnionTags is like recordTag for a union written as a oneof: tags
+// holds the field tag of each member of the oneof, keyed by its type, and is
+// recorded as a JSON object under the path of se.
+func (pf *protofile) recordUnionTags(se *yang.Entry, tags map[string]int) {
+	if pf.tagMap != nil && se.Type != nil && len(se.Dir) == 0 {
+		pf.tagMap[se.FullPath()] = tags
+	}
+}
+
+// writeTagMap writes tags to tagMapFile as a JSON object with the paths
+// sorted.
+func writeTagMap(tags map[string]interface{}) error {
+	b, err := json.MarshalIndent(tags, "", indentString)
+	if err != nil {
+		return err
+	}
+	return writeFile(tagMapFile, func(w io.Writer) {
+		fmt.Fprintf(w, "%s\n", b)
+	})
+}
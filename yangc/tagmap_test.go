@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTagMap(t *testing.T) {
+	defer func(s string) { tagMapFile = s }(tagMapFile)
+	dir, err := ioutil.TempDir("", "yangc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tagMapFile = filepath.Join(dir, "tags.json")
+
+	entries := compileString(t, "tags.yang", `
+module tags {
+  namespace "urn:tags";
+  prefix "t";
+
+  container system {
+    leaf name { type string; }
+    container clock {
+      leaf zone { type string; }
+      leaf offset { type int32; }
+    }
+    leaf address {
+      type union {
+        type uint32;
+        type string;
+      }
+    }
+  }
+}
+`)
+	doProto(ioutil.Discard, entries)
+	data, err := ioutil.ReadFile(tagMapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags map[string]interface{}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		t.Fatalf("%v: %s", err, data)
+	}
+	for path, want := range map[string]interface{}{
+		"/tags:system/name":         1.0,
+		"/tags:system/clock/zone":   1.0,
+		"/tags:system/clock/offset": 2.0,
+		"/tags:system/address":      map[string]interface{}{"string": 3.0, "uint32": 4.0},
+	} {
+		if got, ok := tags[path]; !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got tag %v (present %v), want %v", path, got, ok, want)
+		}
+	}
+	if _, ok := tags["/tags:system/clock"]; ok {
+		t.Errorf("container in tag map: %s", data)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/template"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+// templateFile is the text/template used by the template command.
+var templateFile string
+
+func init() {
+	var templateCmd = &cobra.Command{
+		Use:   "template",
+		Short: "generate output from a Go text/template",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if templateFile == "" {
+				return fmt.Errorf("missing --template")
+			}
+			text, err := ioutil.ReadFile(templateFile)
+			if err != nil {
+				return err
+			}
+			entries := doCompile(yangFileName)
+			return doTemplate(os.Stdout, templateFile, string(text), entries)
+		},
+	}
+	templateCmd.Flags().StringVar(&templateFile, "template", "", "Go text/template file to execute")
+	mainCmd.AddCommand(templateCmd)
+}
+
+// templateFuncs are the functions available to templates in addition to
+// the text/template builtins.
+var templateFuncs = template.FuncMap{
+	// kind returns the kind of entry, such as "Leaf" or "Directory".
+	"kind": func(e *yang.Entry) string { return e.Kind.String() },
+	// isList reports whether the entry is a list or leaf-list.
+	"isList": func(e *yang.Entry) bool { return e.ListAttr != nil },
+	// children returns the children of the entry, in the order selected
+	// by --sort-fields, leaving out RPCs.
+	"children": children,
+	// path returns the schema path of the entry.
+	"path": func(e *yang.Entry) string { return e.FullPath() },
+}
+
+// doTemplate executes the template text, named name, with the module entries
+// as its data and writes the result to w.
+func doTemplate(w io.Writer, name, text string, entries []*yang.Entry) error {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	var mods []*yang.Entry
+	for _, e := range entries {
+		if e != nil {
+			mods = append(mods, e)
+		}
+	}
+	return t.Execute(w, mods)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	entries := compileString(t, "tmpl.yang", `
+module tmpl {
+  namespace "urn:tmpl";
+  prefix "t";
+
+  container system {
+    leaf hostname { type string; }
+    leaf-list dns { type string; }
+    container clock {
+      leaf timezone { type string; }
+    }
+  }
+}
+`)
+	const text = `{{define "leaves"}}{{range children .}}{{if eq (kind .) "Leaf"}}{{.Name}} {{path .}}{{if isList .}} []{{end}}
+{{else}}{{template "leaves" .}}{{end}}{{end}}{{end}}{{range .}}module {{.Name}}
+{{template "leaves" .}}{{end}}`
+	var buf bytes.Buffer
+	if err := doTemplate(&buf, "test", text, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := `module tmpl
+hostname /tmpl:system/hostname
+dns /tmpl:system/dns []
+timezone /tmpl:system/clock/timezone
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := doTemplate(&buf, "bad", "{{unknown .}}", entries); err == nil {
+		t.Error("template with an unknown function did not fail")
+	}
+}
@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"sort"
 
 	"github.com/paranpen/yangc/pkg/indent"
 	"github.com/paranpen/yangc/pkg/yang"
@@ -39,7 +38,9 @@ func init() {
 
 func doTree(w io.Writer, entries []*yang.Entry) {
 	for _, e := range entries {
-		WriteTree(w, e)
+		if e != nil {
+			WriteTree(w, e)
+		}
 	}
 }
 
@@ -49,13 +50,14 @@ func WriteTree(w io.Writer, e *yang.Entry) {
 		fmt.Fprintln(w)
 		fmt.Fprintln(indent.NewWriter(w, "// "), e.Description)
 	}
+	writeReference(w, "", e)
 	if len(e.Exts) > 0 {
 		fmt.Fprintf(w, "extensions: {\n")
 		for _, ext := range e.Exts {
 			if n := ext.NName(); n != "" {
-				fmt.Fprintf(w, "  %s %s;\n", ext.Kind(), n)
+				fmt.Fprintf(w, "%s%s %s;\n", indentString, ext.Kind(), n)
 			} else {
-				fmt.Fprintf(w, "  %s;\n", ext.Kind())
+				fmt.Fprintf(w, "%s%s;\n", indentString, ext.Kind())
 			}
 		}
 		fmt.Fprintln(w, "}")
@@ -91,19 +93,14 @@ func WriteTree(w io.Writer, e *yang.Entry) {
 	}
 	if r := e.RPC; r != nil {
 		if r.Input != nil {
-			WriteTree(indent.NewWriter(w, "  "), r.Input)
+			WriteTree(indent.NewWriter(w, indentString), r.Input)
 		}
 		if r.Output != nil {
-			WriteTree(indent.NewWriter(w, "  "), r.Output)
+			WriteTree(indent.NewWriter(w, indentString), r.Output)
 		}
 	}
-	var names []string
-	for k := range e.Dir {
-		names = append(names, k)
-	}
-	sort.Strings(names)
-	for _, k := range names {
-		WriteTree(indent.NewWriter(w, "  "), e.Dir[k])
+	for _, se := range nameSortedDir(e) {
+		WriteTree(indent.NewWriter(w, indentString), se)
 	}
 	// { to match the brace below to keep brace matching working
 	fmt.Fprintln(w, "}")
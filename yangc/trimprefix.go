@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// trimPrefix is the schema path, such as /module/container, left out of
+// the generated names of the nodes below it.
+var trimPrefix string
+
+func init() {
+	mainCmd.PersistentFlags().StringVar(&trimPrefix, "trim-prefix", "", "schema path (/module/container) to leave out of the generated names of the nodes below it")
+}
+
+// trimSegments returns the names of the nodes in --trim-prefix, starting
+// with the module.  Both /module/container and /module:container are
+// accepted, and any other prefixes are dropped.
+func trimSegments() []string {
+	var segs []string
+	for i, s := range strings.Split(strings.Trim(trimPrefix, "/"), "/") {
+		if j := strings.Index(s, ":"); j >= 0 {
+			if i == 0 {
+				segs = append(segs, s[:j])
+			}
+			s = s[j+1:]
+		}
+		segs = append(segs, s)
+	}
+	return segs
+}
+
+// trimmedAncestor returns the ancestor of e named by --trim-prefix, or nil
+// if e is not below it.  Names built from the path of e stop short of the
+// returned entry.
+func trimmedAncestor(e *yang.Entry) *yang.Entry {
+	if trimPrefix == "" || e == nil {
+		return nil
+	}
+	var path []*yang.Entry
+	for p := e.Parent; p != nil; p = p.Parent {
+		path = append([]*yang.Entry{p}, path...)
+	}
+	segs := trimSegments()
+	if len(path) < len(segs) {
+		return nil
+	}
+	for i, s := range segs {
+		if path[i].Name != s {
+			return nil
+		}
+	}
+	return path[len(segs)-1]
+}
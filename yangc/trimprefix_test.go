@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrimPrefix(t *testing.T) {
+	defer func(b bool) { qualifiedNames = b }(qualifiedNames)
+	defer func(s string) { trimPrefix = s }(trimPrefix)
+	qualifiedNames = true
+
+	entries := compileString(t, "network.yang", `
+module network {
+  namespace "urn:network";
+  prefix "n";
+
+  container network {
+    container interfaces {
+      list interface {
+        key name;
+        leaf name { type string; }
+        container counters { leaf in-octets { type uint64; } }
+      }
+    }
+  }
+  container system { leaf hostname { type string; } }
+}
+`)
+	for _, tt := range []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"message NetworkInterfacesInterfaceCounters {", "message NetworkInterfaces {", "message System {"}},
+		{"/network/network", []string{"message InterfacesInterfaceCounters {", "message Interfaces {", "message Network {", "message System {"}},
+		{"/network:network/interfaces", []string{"message InterfaceCounters {", "message Interface {", "message NetworkInterfaces {"}},
+	} {
+		trimPrefix = tt.prefix
+		var buf bytes.Buffer
+		doProto(&buf, entries)
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%q: missing %q in output:\n%s", tt.prefix, want, buf.String())
+			}
+		}
+	}
+}
@@ -15,10 +15,13 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/paranpen/yangc/pkg/indent"
@@ -50,49 +53,143 @@ var kind2header = map[yang.TypeKind]string{
 	yang.Yunion:              "union",       // handled inline
 }
 
+// headerKind returns the C type of values of kind k, the type of the field
+// for se.  A kind with no mapping falls back to char *, see lookupKind, with
+// a comment prefixed by ind written to w when it is reported.
+func headerKind(w io.Writer, ind string, se *yang.Entry, k yang.TypeKind) string {
+	kind, reported := lookupKind(kind2proto, "char *", se, k)
+	if reported {
+		fmt.Fprintf(w, "%s// *WARNING* unknown type kind %s\n", ind, k)
+	}
+	return kind
+}
+
+// withEnumNames adds name lookup tables for every generated enum.
+var withEnumNames bool
+
+// pack removes the padding from generated structs.
+var pack bool
+
+// baseTypeOnly suppresses typedef blocks, leaving every leaf typed by its
+// base kind alone.
+var baseTypeOnly bool
+
+// enumsFile, when set, is the file the generated C enums are written to.
+// The header includes it in place of the enums.
+var enumsFile string
+
+// enums receives the enums while enumsFile is written, see writeHeader.
+var enums io.Writer
+
+// commentStyle is how descriptions are written in C, either "line" for
+// // comments or "doxygen" for /** */ blocks.
+var commentStyle string
+
+// enumStyle is how enums are generated in C, either "enum" or "define".
+var enumStyle string
+
+// stripComments sanitizes descriptions so they cannot end or break the C
+// comments they are written in, see sanitizeComment.
+var stripComments bool
+
+// wrapUnions writes each union leaf as a struct holding the discriminant
+// and the C union, rather than as two fields.
+var wrapUnions bool
+
 func init() {
 	var headerCmd = &cobra.Command{
 		Use:   "header",
 		Short: "yangc go generate all types in C format",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			entries := doCompile(yangFileName)
-			doHeader(os.Stdout, entries)
+			return writeHeader(os.Stdout, entries, doHeader)
 		},
 	}
 	mainCmd.AddCommand(headerCmd)
 	var typeCmd = &cobra.Command{
 		Use:   "type",
 		Short: "yangc to generate enum types in C format",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			entries := doCompile(yangFileName)
-			doType(os.Stdout, entries)
+			return writeHeader(os.Stdout, entries, doType)
 		},
 	}
 	var tableCmd = &cobra.Command{
 		Use:   "table",
 		Short: "yangc to generate table struct in C format",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			entries := doCompile(yangFileName)
-			doTable(os.Stdout, entries)
+			return writeHeader(os.Stdout, entries, doTable)
 		},
 	}
 	headerCmd.AddCommand(typeCmd, tableCmd)
+	headerCmd.PersistentFlags().BoolVar(&withEnumNames, "with-enum-names", false, "emit a name lookup table for each enum")
+	headerCmd.PersistentFlags().BoolVar(&inlineImportedTypes, "inline-imported-types", false, "emit the imported typedefs used by a module in its output")
+	headerCmd.PersistentFlags().StringVar(&enumStyle, "enum-style", "enum", "how enums are generated: enum or define (C89 #define constants)")
+	headerCmd.PersistentFlags().StringVar(&commentStyle, "comment-style", "line", "how descriptions are written: line (// comments) or doxygen (/** */ blocks with @brief)")
+	headerCmd.PersistentFlags().BoolVar(&pack, "pack", false, "pack the generated structs so they can be mapped onto network buffers")
+	headerCmd.PersistentFlags().BoolVar(&baseTypeOnly, "base-type-only", false, "emit no typedefs, only the base kinds of leaves")
+	headerCmd.PersistentFlags().StringVar(&enumsFile, "enums-file", "", "write the enums to this file and include it from the header")
+	headerCmd.PersistentFlags().BoolVar(&stripComments, "strip-comments-from-descriptions", false, "break up */, /* and line ending backslashes in descriptions so they cannot end the C comments")
+	headerCmd.PersistentFlags().BoolVar(&groupWhen, "group-when", false, "group the fields of structs by their when expression in #if blocks naming it")
+	headerCmd.PersistentFlags().BoolVar(&wrapUnions, "wrap-unions-in-struct", false, "write each union as a struct of its kind and a C union")
+	headerCmd.PersistentFlags().BoolVar(&bitMasks, "bit-masks", false, "store bits of up to 64 positions as uint64 with a #define mask for each bit")
+	headerCmd.PersistentFlags().BoolVar(&withIdentityTree, "with-identity-tree", false, "emit the identity hierarchy of each module as an enum")
+}
+
+// writeHeader writes the output of gen for entries to w.  With --enums-file
+// the enums gen generates are written to that file instead.
+func writeHeader(w io.Writer, entries []*yang.Entry, gen func(io.Writer, []*yang.Entry)) error {
+	if enumsFile == "" {
+		gen(w, entries)
+		return nil
+	}
+	return writeFile(enumsFile, func(ew io.Writer) {
+		fmt.Fprintf(ew, "// Automatically generated by yangc\n")
+		enums = ew
+		gen(w, entries)
+		enums = nil
+	})
+}
+
+// writeEnumsInclude writes an include of the enums file to w if the enums
+// are written to one.
+func writeEnumsInclude(w io.Writer) {
+	if enums != nil {
+		fmt.Fprintf(w, "#include %q\n", enumsFile)
+	}
 }
 
 // doHeader generate all types from entries tree
 func doHeader(w io.Writer, entries []*yang.Entry) {
+	globals := map[string]*yang.Entry{}
 	for _, e := range entries {
-		if len(e.Dir) == 0 {
+		if e == nil || len(e.Dir) == 0 {
 			continue // skip modules that have nothing in them
 		}
 		pf := &protofile{
 			fixedNames: map[string]string{},
 			messages:   map[string]*messageInfo{},
+			globals:    globals,
 		}
 		pf.printHeader(w, e, false)
-		for _, se := range e.Dir {
-			pf.WriteHeaders(w, se, true, true)
+		writeEnumsInclude(w)
+		if inlineImportedTypes {
+			pf.writeImportedTypedefs(w, e)
+		}
+		if withIdentityTree {
+			pf.writeIdentityTree(w, e)
 		}
+		writePackBegin(w)
+		var body bytes.Buffer
+		for _, se := range sortedDir(e) {
+			pf.WriteHeaders(&body, se, true, true)
+		}
+		pf.writeForwardDeclarations(w)
+		w.Write(pf.masks.Bytes())
+		w.Write(body.Bytes())
+		writePackEnd(w)
+		countGenerated(pf)
 	}
 	/* types := Types{}
 	for _, e := range entries {
@@ -111,75 +208,161 @@ func doHeader(w io.Writer, entries []*yang.Entry) {
 
 // doEnum generate enum file from node tree
 func doType(w io.Writer, entries []*yang.Entry) {
+	globals := map[string]*yang.Entry{}
 	for _, e := range entries {
-		if len(e.Dir) == 0 {
+		if e == nil || len(e.Dir) == 0 {
 			continue // skip modules that have nothing in them
 		}
 		pf := &protofile{
 			fixedNames: map[string]string{},
 			messages:   map[string]*messageInfo{},
+			globals:    globals,
 		}
 		pf.printHeader(w, e, false)
-		for _, se := range e.Dir {
+		writeEnumsInclude(w)
+		if inlineImportedTypes {
+			pf.writeImportedTypedefs(w, e)
+		}
+		if withIdentityTree {
+			pf.writeIdentityTree(w, e)
+		}
+		for _, se := range sortedDir(e) {
 			pf.WriteHeaders(w, se, true, false)
 		}
+		countGenerated(pf)
 	}
 }
 
 // doTable generate enum file from node tree
 func doTable(w io.Writer, entries []*yang.Entry) {
+	globals := map[string]*yang.Entry{}
 	for _, e := range entries {
-		if len(e.Dir) == 0 {
+		if e == nil || len(e.Dir) == 0 {
 			// skip modules that have nothing in them
 			continue
 		}
 		pf := &protofile{
 			fixedNames: map[string]string{},
 			messages:   map[string]*messageInfo{},
+			globals:    globals,
 		}
 		pf.printHeader(w, e, false)
-		for _, se := range e.Dir {
-			pf.WriteHeaders(w, se, false, true)
+		writeEnumsInclude(w)
+		writePackBegin(w)
+		var body bytes.Buffer
+		for _, se := range sortedDir(e) {
+			pf.WriteHeaders(&body, se, false, true)
 		}
+		pf.writeForwardDeclarations(w)
+		w.Write(pf.masks.Bytes())
+		w.Write(body.Bytes())
+		writePackEnd(w)
+		countGenerated(pf)
 	}
 }
 
 // Children returns all the children nodes of e that are not RPC nodes.
 func childrenEntries(e *yang.Entry) []*yang.Entry {
-	var names []string
-	for k, se := range e.Dir {
+	var children []*yang.Entry
+	for _, se := range e.Dir {
 		if se.RPC == nil {
-			names = append(names, k)
+			children = append(children, se)
 		}
 	}
-	if len(names) == 0 {
-		return nil
+	sortEntries(children)
+	return children
+}
+
+// declare records that a struct named name is written for e and returns
+// name.
+func (pf *protofile) declare(e *yang.Entry, name string) string {
+	pf.structs = append(pf.structs, name)
+	return pf.global(e, name)
+}
+
+// global records that the file scope identifier name is written for e and
+// returns name.  C has a single scope for the structs and enums of a file,
+// so an error is reported if name was already written for another entry,
+// possibly of another module written to the same file.
+func (pf *protofile) global(e *yang.Entry, name string) string {
+	if pf.globals == nil {
+		return name
+	}
+	if o := pf.globals[name]; o != nil && o != e {
+		reportError(fmt.Errorf("%s: %s of %s collides with %s of %s", yang.Source(e.Node), name, e.Path(), name, o.Path()))
+	}
+	pf.globals[name] = e
+	return name
+}
+
+// writeForwardDeclarations writes a forward declaration of each struct
+// declared so far to w, so the structs can refer to each other no matter
+// the order they are defined in.
+func (pf *protofile) writeForwardDeclarations(w io.Writer) {
+	if len(pf.structs) == 0 {
+		return
 	}
-	// sort.Strings(names)
-	children := make([]*yang.Entry, len(names))
-	for x, n := range names {
-		children[x] = e.Dir[n]
+	fmt.Fprintln(w)
+	for _, name := range pf.structs {
+		fmt.Fprintf(w, "struct %s;\n", name)
+	}
+}
+
+// countTopLevel counts e, written as a typedef or struct, in the types of
+// pf if it is a top-level type rather than one nested in a struct.
+func (pf *protofile) countTopLevel(e *yang.Entry) {
+	if e.Parent == nil || e.Parent.Parent == nil {
+		pf.types++
+	}
+}
+
+// writeRPCStructs writes the input and output of the RPC e to w as the
+// structs NameRequest and NameResponse.  An RPC without an input or output
+// gets an empty struct in its place.  Only the enums of the input and
+// output are written unless listPrint is set.
+func (pf *protofile) writeRPCStructs(w io.Writer, e *yang.Entry, typePrint, listPrint bool) {
+	name := pf.fixName(e.Name)
+	for _, m := range []struct {
+		e      *yang.Entry
+		suffix string
+	}{
+		{e.RPC.Input, "Request"},
+		{e.RPC.Output, "Response"},
+	} {
+		if m.e != nil {
+			// Rename a copy, the entry is shared with the other
+			// generators.
+			c := *m.e
+			c.Name = name + m.suffix
+			pf.WriteHeaders(w, &c, typePrint, listPrint)
+			continue
+		}
+		if listPrint {
+			writeDescription(w, "\n", e.Description)
+			fmt.Fprintf(w, "struct %s {\n}\n", pf.declare(e, name+m.suffix))
+			pf.types++
+		}
 	}
-	return children
 }
 
 // WriteTypedefs print all typedefs
 func (pf *protofile) WriteHeaders(w io.Writer, e *yang.Entry, typePrint bool, listPrint bool) {
-	messageName := pf.fullName(e)
-	mi := pf.messages[messageName]
-	if mi == nil {
-		mi = &messageInfo{
-			fields: map[string]int{},
-		}
-		pf.messages[messageName] = mi
+	ind := indentString
+
+	if e.RPC != nil {
+		pf.writeRPCStructs(w, e, typePrint, listPrint)
+		return
 	}
 
+	messageName := pf.fullName(e)
+	mi := pf.messageTags(messageName)
+
 	if e.GetKind() == "Typedef" {
-		if typePrint {
-			if e.Description != "" {
-				fmt.Fprintln(indent.NewWriter(w, "\n// "), e.Description)
-			}
-			fmt.Fprintf(w, "typedef %s {\n", pf.messageName(e)) // matching brace }
+		if typePrint && !baseTypeOnly {
+			writeDescription(w, "\n", e.Description)
+			writeReference(w, "", e)
+			fmt.Fprintf(w, "typedef %s {\n", pf.global(e, pf.messageName(e))) // matching brace }
+			pf.countTopLevel(e)
 			printNodeTypedef(w, e.Node)
 			fmt.Fprintf(w, "}\n") // { to match the brace below to keep brace matching working
 		}
@@ -187,53 +370,412 @@ func (pf *protofile) WriteHeaders(w io.Writer, e *yang.Entry, typePrint bool, li
 	}
 
 	if listPrint {
-		if e.Description != "" {
-			fmt.Fprintln(indent.NewWriter(w, "\n// "), e.Description)
-		}
-		fmt.Fprintf(w, "struct %s {\n", pf.messageName(e)) // matching brace }
+		writeDescription(w, "\n", e.Description)
+		writeReference(w, "", e)
+		fmt.Fprintf(w, "struct %s {\n", pf.declare(e, pf.messageName(e))) // matching brace }
+		pf.countTopLevel(e)
+		writeMountPoint(w, ind, e)
+		writeExtensions(w, ind, e)
+		writeUnique(w, ind, e)
 	}
 
+	// With --group-when each field is written to a buffer of its own, so
+	// the tags are assigned in declaration order, and the buffers are
+	// then written grouped by their when expression.
 	nodes := childrenEntries(e)
+	out := w
+	fields := map[*yang.Entry]*bytes.Buffer{}
 	for _, se := range nodes {
+		w := out
+		if groupWhen {
+			fields[se] = &bytes.Buffer{}
+			w = fields[se]
+		}
 		var kind string
-		if se.Type.Kind == yang.Yenum {
+		st := fieldType(se)
+		if st != nil && st.Kind == yang.Yenum {
 			if typePrint {
-				kind = pf.fixName(se.Name)
-				fmt.Fprintf(w, "  enum %s {", kind)
+				kind = pf.global(se, pf.fixName(se.Name))
+				var source string
 				if protoWithSource {
-					fmt.Fprintf(w, " // %s", yang.Source(se.Node))
+					source = yang.Source(se.Node)
 				}
-				fmt.Fprintln(w)
-
-				for i, n := range se.Type.Enum.Names() {
-					fmt.Fprintf(w, "    %s_%s = %d;\n", kind, strings.ToUpper(pf.fieldName(n)), i)
+				// Members keep their declared values, ordered by
+				// value and then by declaration.  A member sharing
+				// the value of one declared before it is an alias.
+				names := st.Enum.DeclaredNames()
+				sort.SliceStable(names, func(i, j int) bool {
+					return st.Enum.Value(names[i]) < st.Enum.Value(names[j])
+				})
+				values := make([]int64, len(names))
+				members := make([]cEnumMember, len(names))
+				first := map[int64]string{}
+				seen := map[string]string{}
+				for i, n := range names {
+					values[i] = st.Enum.Value(n)
+					members[i] = cEnumMember{name: pf.enumMemberName(se.Node, kind, seen, n), value: values[i]}
+					if f, ok := first[values[i]]; ok {
+						members[i].comment = "alias of " + kind + "_" + f
+					} else {
+						first[values[i]] = members[i].name
+					}
+				}
+				writeCEnum(w, ind, kind, source, members)
+				if withEnumNames {
+					writeEnumNames(indent.NewWriter(w, ind), kind, names, values)
 				}
-				fmt.Fprintf(w, "  };\n")
 			}
+		} else if st != nil && st.Kind == yang.Yunion && typePrint {
+			pf.writeCUnion(w, se, st, mi, listPrint)
 		} else {
 			if listPrint {
-				if se.Description != "" {
-					fmt.Fprintln(indent.NewWriter(w, "  // "), se.Description)
+				writeDescription(w, ind, se.Description)
+				writeReference(w, ind, se)
+				if se.Type != nil {
+					writeExtensions(w, ind, se)
 				}
 				if len(se.Dir) > 0 || se.Type == nil {
-					pf.WriteHeaders(indent.NewWriter(w, "  "), se, typePrint, listPrint)
+					pf.WriteHeaders(indent.NewWriter(w, ind), se, typePrint, listPrint)
 				}
+				k := se.Name
+				name := pf.fieldName(k)
 				if len(se.Dir) > 0 || se.Type == nil {
 					kind = pf.messageName(se)
+				} else if bitMasks && st.Kind == yang.Ybits && fitsBitMask(st) {
+					// The defines are written at file
+					// scope, before the structs.
+					pf.writeBitMasks(&pf.masks, strings.ToUpper(pf.fullName(e))+"_"+strings.ToUpper(name), st)
+					kind = "uint64"
 				} else {
-					kind = kind2proto[se.Type.Kind]
+					kind = headerKind(w, ind, se, st.Kind)
 				}
-				k := se.Name
-				name := pf.fieldName(k)
-				fmt.Fprintf(w, "%s %s = %d;\n", kind, name, mi.tag(name, kind, se.ListAttr != nil))
+				if isDeprecated(se) {
+					fmt.Fprintf(w, "%s// DEPRECATED\n", ind)
+				}
+				if st != nil && st.Kind == yang.Ydecimal64 {
+					// The value is stored as an integer
+					// scaled by 10^fraction-digits.
+					fmt.Fprintf(w, "#define %s_%s_SCALE %d\n", strings.ToUpper(pf.fullName(e)), strings.ToUpper(name), scale(st.FractionDigits))
+				}
+				var dim string
+				if n, ok := fixedLength(st); ok && kind == "bytes" {
+					// A binary of a single length is a
+					// fixed-size array.
+					kind = "uint8_t"
+					dim = fmt.Sprintf("[%d]", n)
+				}
+				fmt.Fprintf(w, "%s %s%s = %d;%s\n", kind, name, dim, mi.tag(name, kind, se.ListAttr != nil), fieldComment(se))
 			}
 		}
 	}
+	if groupWhen {
+		var when string
+		for _, se := range groupByWhen(nodes) {
+			when = switchWhen(w, when, nodeValue(se, "When"))
+			w.Write(fields[se].Bytes())
+		}
+		switchWhen(w, when, "")
+	}
 	if listPrint {
 		fmt.Fprintln(w, "}") // { to match the brace below to keep brace matching working
 	}
 }
 
+// A cEnumMember is a member of a generated C enum.
+type cEnumMember struct {
+	name    string // name of the member, without the enum prefix
+	value   int64
+	comment string // optional comment following the member
+}
+
+// writeCEnum writes the enum kind with members to w, with each line
+// prefixed by ind, or unindented to enums if it is set.  With --enum-style
+// define the members are written as #define constants along with a typedef
+// of kind to an integer type.  The optional comment follows the first line.
+func writeCEnum(w io.Writer, ind, kind, comment string, members []cEnumMember) {
+	if enums != nil {
+		w, ind = enums, ""
+	}
+	note := func(c string) string {
+		if c == "" {
+			return ""
+		}
+		return " // " + c
+	}
+	if enumStyle == "define" {
+		storage := "uint32"
+		for _, m := range members {
+			if m.value < 0 {
+				storage = "int32"
+			}
+		}
+		fmt.Fprintf(w, "%stypedef %s %s;%s\n", ind, storage, kind, note(comment))
+		for _, m := range members {
+			fmt.Fprintf(w, "%s#define %s_%s %d%s\n", ind, kind, m.name, m.value, note(m.comment))
+		}
+		return
+	}
+	fmt.Fprintf(w, "%senum %s {%s\n", ind, kind, note(comment)) // matching brace }
+	for _, m := range members {
+		fmt.Fprintf(w, "%s%s%s_%s = %d;%s\n", ind, indentString, kind, m.name, m.value, note(m.comment))
+	}
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintf(w, "%s};\n", ind)
+}
+
+// cEnumType returns the C type of the generated enum kind.
+func cEnumType(kind string) string {
+	if enumStyle == "define" {
+		return kind
+	}
+	return "enum " + kind
+}
+
+// writeCUnion writes the union leaf se, of type st, to w as a C union along
+// with an enum, the discriminant, naming the member of the union that is set.
+// Only the enum is written unless listPrint is set.
+func (pf *protofile) writeCUnion(w io.Writer, se *yang.Entry, st *yang.YangType, mi *messageInfo, listPrint bool) {
+	ind := indentString
+	types := pf.unionTypes(se, st, map[string]bool{})
+	if len(types) == 0 {
+		fmt.Fprintf(w, "%s// *WARNING* union %s has no types\n", ind, se.Name)
+		warn(fmt.Errorf("%s: union %s has no types", yang.Source(se.Node), se.Name))
+		return
+	}
+	name := pf.fieldName(se.Name)
+	kind := pf.global(se, pf.fixName(se.Name)+"Kind")
+	members := make([]cEnumMember, len(types))
+	for i, t := range types {
+		members[i] = cEnumMember{name: strings.ToUpper(pf.fieldName(t)), value: int64(i)}
+	}
+	writeCEnum(w, ind, kind, "", members)
+	if !listPrint {
+		return
+	}
+	writeDescription(w, ind, se.Description)
+	writeReference(w, ind, se)
+	writeExtensions(w, ind, se)
+	if isDeprecated(se) {
+		fmt.Fprintf(w, "%s// DEPRECATED\n", ind)
+	}
+	if wrapUnions {
+		pf.writeCTaggedUnion(w, se, kind, types, mi)
+		return
+	}
+	fmt.Fprintf(w, "%s %s_kind = %d;\n", cEnumType(kind), name, mi.tag(name+"_kind", kind, false))
+	fmt.Fprintf(w, "union {\n") // matching brace }
+	for _, t := range types {
+		fmt.Fprintf(w, "%s%s %s_%s;\n", ind, t, name, pf.fieldName(t))
+	}
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintf(w, "} %s = %d;%s\n", name, mi.tag(name, "union", se.ListAttr != nil), fieldComment(se))
+}
+
+// writeCTaggedUnion writes the union leaf se, with the members types, to w
+// as a struct holding the discriminant of enum kind along with the union,
+// followed by the field of that struct.
+func (pf *protofile) writeCTaggedUnion(w io.Writer, se *yang.Entry, kind string, types []string, mi *messageInfo) {
+	ind := indentString
+	name := pf.fieldName(se.Name)
+	// The struct is nested, so it is not forward declared.
+	sname := pf.global(se, pf.fixName(se.Name))
+	fmt.Fprintf(w, "struct %s {\n", sname) // matching brace }
+	fmt.Fprintf(w, "%s%s kind;\n", ind, cEnumType(kind))
+	fmt.Fprintf(w, "%sunion {\n", ind) // matching brace }
+	for _, t := range types {
+		fmt.Fprintf(w, "%s%s%s %s_%s;\n", ind, ind, t, name, pf.fieldName(t))
+	}
+	// { to match the braces below to keep brace matching working
+	fmt.Fprintf(w, "%s} value;\n", ind)
+	fmt.Fprintf(w, "};\n")
+	fmt.Fprintf(w, "struct %s %s = %d;%s\n", sname, name, mi.tag(name, "union", se.ListAttr != nil), fieldComment(se))
+}
+
+// checkEnumStyle returns an error if --enum-style is not a known style.
+func checkEnumStyle() error {
+	switch enumStyle {
+	case "enum", "define":
+		return nil
+	}
+	return fmt.Errorf("invalid --enum-style %q: want enum or define", enumStyle)
+}
+
+// checkCommentStyle returns an error if --comment-style is not a known
+// style.
+func checkCommentStyle() error {
+	switch commentStyle {
+	case "line", "doxygen":
+		return nil
+	}
+	return fmt.Errorf("invalid --comment-style %q: want line or doxygen", commentStyle)
+}
+
+// writeDescription writes the description desc, if any, to w as a comment
+// with each line prefixed by ind.  Newlines leading ind are written once,
+// before the comment.  With --comment-style doxygen the comment is a /** */
+// block whose first sentence is the @brief.
+func writeDescription(w io.Writer, ind, desc string) {
+	if desc == "" {
+		return
+	}
+	if stripComments {
+		desc = sanitizeComment(desc)
+	}
+	lead := strings.TrimLeft(ind, "\n")
+	fmt.Fprint(w, ind[:len(ind)-len(lead)])
+	if commentStyle != "doxygen" {
+		fmt.Fprintln(indent.NewWriter(w, lead+"// "), desc)
+		return
+	}
+	brief, body := splitBrief(desc)
+	fmt.Fprintf(w, "%s/**\n", lead)
+	fmt.Fprintf(w, "%s * @brief %s\n", lead, brief)
+	if body != "" {
+		fmt.Fprintf(w, "%s *\n", lead)
+		fmt.Fprintln(indent.NewWriter(w, lead+" * "), body)
+	}
+	fmt.Fprintf(w, "%s */\n", lead)
+}
+
+// writeLineComment writes s to w as a // comment.  With
+// --strip-comments-from-descriptions s is sanitized and each of its lines
+// prefixed by "// ".
+func writeLineComment(w io.Writer, s string) {
+	if !stripComments {
+		fmt.Fprintf(w, "// %s\n", s)
+		return
+	}
+	fmt.Fprintln(indent.NewWriter(w, "// "), sanitizeComment(s))
+}
+
+// sanitizeComment returns s with the sequences that would end or malform the
+// C comment it is written in broken up: */ and /* are split, carriage
+// returns dropped and backslashes ending a line, even when followed by
+// spaces, which would continue a // comment onto the next, removed.
+func sanitizeComment(s string) string {
+	// Split */ first, so splitting /* cannot make one.
+	s = strings.Replace(s, "*/", "* /", -1)
+	s = strings.Replace(s, "/*", "/ *", -1)
+	s = strings.Replace(s, "\r", "", -1)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		// A backslash followed by spaces still splices lines.
+		lines[i] = strings.TrimRight(l, " \t\\")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitBrief splits the description desc into its first sentence, on a
+// single line, and the rest.
+func splitBrief(desc string) (brief, body string) {
+	desc = strings.TrimSpace(desc)
+	end := len(desc)
+	for i := 0; i+1 < len(desc); i++ {
+		if desc[i] == '.' && (desc[i+1] == ' ' || desc[i+1] == '\n' || desc[i+1] == '\t') {
+			end = i + 1
+			break
+		}
+	}
+	return strings.Join(strings.Fields(desc[:end]), " "), strings.TrimSpace(desc[end:])
+}
+
+// writePackBegin starts packing the structs written to w if --pack is set.
+func writePackBegin(w io.Writer) {
+	if pack {
+		fmt.Fprintf(w, "\n// Structs are packed without padding.  Multi-byte fields are in host\n")
+		fmt.Fprintf(w, "// byte order, convert them with htons/htonl when mapping onto network buffers.\n")
+		fmt.Fprintf(w, "#pragma pack(push, 1)\n")
+	}
+}
+
+// writePackEnd ends packing started by writePackBegin.
+func writePackEnd(w io.Writer) {
+	if pack {
+		fmt.Fprintf(w, "#pragma pack(pop)\n")
+	}
+}
+
+// fixedLength returns the length of st if it is a binary that is restricted
+// to exactly one length.
+func fixedLength(st *yang.YangType) (uint64, bool) {
+	if st == nil || st.Kind != yang.Ybinary || len(st.Length) != 1 {
+		return 0, false
+	}
+	r := st.Length[0]
+	if r.Min.Kind != yang.Positive || !r.Min.Equal(r.Max) {
+		return 0, false
+	}
+	return r.Min.Value, true
+}
+
+// scale returns 10 to the power of digits, the scale of a decimal64 with
+// digits fraction-digits.
+func scale(digits int) uint64 {
+	s := uint64(1)
+	for i := 0; i < digits; i++ {
+		s *= 10
+	}
+	return s
+}
+
+// definitions, when not nil, receives the definitions of the generated
+// header, such as enum name tables, leaving only their declarations in the
+// header itself.  It is set by generate --split-source.
+var definitions io.Writer
+
+// writeEnumNames writes a lookup of the YANG names of the members of enum
+// kind, where names[i] has the value values[i].  When the values are
+// contiguous starting at 0 the lookup is an array indexed by value,
+// otherwise it is a function switching on the value.  When definitions is
+// set the lookup is written there and only its declaration to w.
+func writeEnumNames(w io.Writer, kind string, names []string, values []int64) {
+	byValue := map[int64]string{}
+	var sorted []int64
+	for i, v := range values {
+		if _, ok := byValue[v]; !ok {
+			byValue[v] = names[i]
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	contiguous := len(sorted) == len(values)
+	for i, v := range sorted {
+		if v != int64(i) {
+			contiguous = false
+			break
+		}
+	}
+	storage, out := "static ", w
+	if definitions != nil {
+		storage, out = "", definitions
+	}
+	if contiguous {
+		quoted := make([]string, len(sorted))
+		for i, v := range sorted {
+			quoted[i] = strconv.Quote(byValue[v])
+		}
+		if definitions != nil {
+			fmt.Fprintf(w, "extern const char *%sName[];\n", kind)
+		}
+		fmt.Fprintf(out, "%sconst char *%sName[] = { %s };\n", storage, kind, strings.Join(quoted, ", "))
+		return
+	}
+	if definitions != nil {
+		fmt.Fprintf(w, "const char *%sName(%s v);\n", kind, cEnumType(kind))
+	}
+	fmt.Fprintf(out, "%sconst char *%sName(%s v) {\n", storage, kind, cEnumType(kind)) // matching brace }
+	fmt.Fprintf(out, "%sswitch (v) {\n", indentString)                                 // matching brace }
+	for _, v := range sorted {
+		fmt.Fprintf(out, "%scase %d: return %q;\n", indentString, v, byValue[v])
+	}
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintf(out, "%s}\n", indentString)
+	fmt.Fprintf(out, "%sreturn NULL;\n", indentString)
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintf(out, "}\n")
+}
+
 // printTypedefs prints node n to w, recursively.
 // TODO(borman): display more information
 func printNodeTypedef(w io.Writer, n yang.Node) {
@@ -290,12 +832,12 @@ Loop:
 			n = f.Interface().(yang.Node)
 			if v, ok := n.(*yang.Value); ok {
 				if ft.Name == "Description" {
-					fmt.Fprintf(w, "// %s\n", v.Name)
+					writeLineComment(w, v.Name)
 				} else {
 					fmt.Fprintf(w, "%s, ", v.Name)
 				}
 			} else {
-				printNodeTypedef(indent.NewWriter(w, "    "), n)
+				printNodeTypedef(indent.NewWriter(w, indentString+indentString), n)
 			}
 		case reflect.Slice:
 			sl := f.Len()
@@ -303,12 +845,12 @@ Loop:
 				n = f.Index(i).Interface().(yang.Node)
 				if v, ok := n.(*yang.Value); ok {
 					if ft.Name == "Description" {
-						fmt.Fprintf(w, "// %s\n", v.Name)
+						writeLineComment(w, v.Name)
 					} else {
 						fmt.Fprintf(w, "%s[%d] = %s\n", ft.Name, i, v.Name)
 					}
 				} else {
-					printNodeTypedef(indent.NewWriter(w, "    "), n)
+					printNodeTypedef(indent.NewWriter(w, indentString+indentString), n)
 				}
 			}
 		}
@@ -348,7 +890,7 @@ Loop:
 			for i := 0; i < sl; i++ {
 				n = f.Index(i).Interface().(yang.Node)
 				if _, ok := n.(*yang.Value); !ok {
-					printEnumType(indent.NewWriter(w, "    "), n)
+					printEnumType(indent.NewWriter(w, indentString+indentString), n)
 					fmt.Fprintf(w, "\n")
 				}
 			}
@@ -357,7 +899,13 @@ Loop:
 			if ft.Name == "Description" {
 				n = f.Interface().(yang.Node)
 				if v, ok := n.(*yang.Value); ok {
-					fmt.Fprintf(w, " // %s", v.Name)
+					d := v.Name
+					if stripComments {
+						// The comment ends the line,
+						// so it must be kept to one.
+						d = sanitizeComment(strings.Join(strings.Fields(d), " "))
+					}
+					fmt.Fprintf(w, " // %s", d)
 				}
 			} else if ft.Name == "Value" {
 				n = f.Interface().(yang.Node)
@@ -445,3 +993,15 @@ func showall(w io.Writer, e *yang.Entry) {
 		showall(w, d)
 	}
 } */
+
+// writeUnique writes a comment, prefixed by ind, to w for each unique
+// statement of the list e.  The constraints are left to validators.
+func writeUnique(w io.Writer, ind string, e *yang.Entry) {
+	l, ok := e.Node.(*yang.List)
+	if !ok {
+		return
+	}
+	for _, u := range l.Unique {
+		fmt.Fprintf(w, "%s// unique: %s\n", ind, strings.Join(strings.Fields(u.Name), " "))
+	}
+}
@@ -0,0 +1,818 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+const indentModule = `
+module indent-test {
+  namespace "urn:indent-test";
+  prefix "it";
+
+  list board {
+    key id;
+    leaf id { type uint32; }
+    leaf state {
+      type enumeration {
+        enum up;
+        enum down;
+      }
+    }
+  }
+}
+`
+
+func TestHeaderIndentTab(t *testing.T) {
+	defer func(s string) { indentString = s }(indentString)
+	var err error
+	if indentString, err = parseIndent("tab"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	doHeader(&buf, compileString(t, "indent-test.yang", indentModule))
+	got := buf.String()
+	for _, want := range []string{
+		"\n\tenum State {",
+		"\n\t\tState_DOWN = 1;",
+		"\n\t};",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\n  enum") {
+		t.Errorf("found space indentation in output:\n%s", got)
+	}
+}
+
+func TestHeaderEnumNames(t *testing.T) {
+	defer func(b bool) { withEnumNames = b }(withEnumNames)
+	withEnumNames = true
+
+	var buf bytes.Buffer
+	doHeader(&buf, compileString(t, "indent-test.yang", indentModule))
+	if want := `  static const char *StateName[] = { "up", "down" };`; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestWriteEnumNames(t *testing.T) {
+	for _, tt := range []struct {
+		names  []string
+		values []int64
+		want   string
+	}{
+		{
+			names:  []string{"up", "down"},
+			values: []int64{1, 0},
+			want:   `static const char *StateName[] = { "down", "up" };` + "\n",
+		},
+		{
+			names:  []string{"low", "high"},
+			values: []int64{1, 100},
+			want: `static const char *StateName(enum State v) {
+  switch (v) {
+  case 1: return "low";
+  case 100: return "high";
+  }
+  return NULL;
+}
+`,
+		},
+	} {
+		var buf bytes.Buffer
+		writeEnumNames(&buf, "State", tt.names, tt.values)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%v: got:\n%s\nwant:\n%s", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderSparseEnumNames(t *testing.T) {
+	defer func(b bool) { withEnumNames = b }(withEnumNames)
+	withEnumNames = true
+
+	entries := compileString(t, "sparse.yang", `
+module sparse {
+  namespace "urn:sparse";
+  prefix "s";
+
+  container link {
+    leaf speed {
+      type enumeration {
+        enum slow { value 1; }
+        enum fast { value 5; }
+        enum max { value 100; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	want := `  static const char *SpeedName(enum Speed v) {
+    switch (v) {
+    case 1: return "slow";
+    case 5: return "fast";
+    case 100: return "max";
+    }
+    return NULL;
+  }
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+	if strings.Contains(buf.String(), "SpeedName[]") {
+		t.Errorf("found name array for sparse enum:\n%s", buf.String())
+	}
+}
+
+func TestHeaderDecimal64Scale(t *testing.T) {
+	e := compileString(t, "money.yang", `
+module money {
+  namespace "urn:money";
+  prefix "m";
+
+  list account {
+    key id;
+    leaf id { type uint32; }
+    leaf balance {
+      type decimal64 { fraction-digits 2; }
+    }
+    leaf rate {
+      type decimal64 { fraction-digits 4; }
+    }
+  }
+}
+`)[0]
+	var buf bytes.Buffer
+	doHeader(&buf, []*yang.Entry{e})
+	for _, want := range []string{
+		"\n#define ACCOUNT_BALANCE_SCALE 100\n",
+		"\n#define ACCOUNT_RATE_SCALE 10000\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+	if got, want := scale(18), uint64(1000000000000000000); got != want {
+		t.Errorf("scale(18) = %d, want %d", got, want)
+	}
+}
+
+func TestHeaderPack(t *testing.T) {
+	defer func(b bool) { pack = b }(pack)
+
+	for _, p := range []bool{false, true} {
+		pack = p
+		var buf bytes.Buffer
+		doHeader(&buf, compileString(t, "indent-test.yang", indentModule))
+		out := buf.String()
+		begin := strings.Index(out, "#pragma pack(push, 1)\n")
+		end := strings.Index(out, "#pragma pack(pop)\n")
+		st := strings.Index(out, "struct ")
+		if !p {
+			if begin >= 0 || end >= 0 {
+				t.Errorf("packed without --pack:\n%s", out)
+			}
+			continue
+		}
+		if begin < 0 || end < 0 || st < begin || st > end {
+			t.Errorf("struct not wrapped in pack pragmas:\n%s", out)
+		}
+	}
+}
+
+func TestHeaderUnion(t *testing.T) {
+	entries := compileString(t, "union.yang", `
+module union {
+  namespace "urn:union";
+  prefix "u";
+
+  container peer {
+    leaf address { type union { type int32; type string; } }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	want := `  enum AddressKind {
+    AddressKind_INT32 = 0;
+    AddressKind_STRING = 1;
+  };
+enum AddressKind address_kind = 1;
+union {
+  int32 address_int32;
+  string address_string;
+} address = 2;
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	doTable(&buf, entries)
+	if strings.Contains(buf.String(), "union {") {
+		t.Errorf("table generated a C union:\n%s", buf.String())
+	}
+}
+
+func TestHeaderWrapUnions(t *testing.T) {
+	defer func(b bool) { wrapUnions = b }(wrapUnions)
+	wrapUnions = true
+
+	entries := compileString(t, "union.yang", `
+module union {
+  namespace "urn:union";
+  prefix "u";
+
+  container peer {
+    leaf address { type union { type int32; type string; } }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	want := `  enum AddressKind {
+    AddressKind_INT32 = 0;
+    AddressKind_STRING = 1;
+  };
+struct Address {
+  enum AddressKind kind;
+  union {
+    int32 address_int32;
+    string address_string;
+  } value;
+};
+struct Address address = 1;
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	if strings.Contains(buf.String(), "address_kind") {
+		t.Errorf("discriminant written outside the struct:\n%s", buf.String())
+	}
+	// Only the top-level struct is forward declared.
+	if strings.Contains(buf.String(), "struct Address;") {
+		t.Errorf("nested struct forward declared:\n%s", buf.String())
+	}
+}
+
+func TestHeaderEnumStyle(t *testing.T) {
+	defer func(s string) { enumStyle = s }(enumStyle)
+
+	entries := compileString(t, "color.yang", `
+module color {
+  namespace "urn:color";
+  prefix "c";
+
+  container paint {
+    leaf color { type enumeration { enum red; enum green; } }
+  }
+}
+`)
+	for _, tt := range []struct {
+		style string
+		want  []string
+	}{
+		{"enum", []string{"enum Color {", "Color_RED = 0;", "Color_GREEN = 1;"}},
+		{"define", []string{"typedef uint32 Color;", "#define Color_RED 0\n", "#define Color_GREEN 1\n"}},
+	} {
+		enumStyle = tt.style
+		if err := checkEnumStyle(); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		doHeader(&buf, entries)
+		out := buf.String()
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: missing %q in:\n%s", tt.style, want, out)
+			}
+		}
+		if tt.style == "define" && strings.Contains(out, "enum ") {
+			t.Errorf("define: enum in C89 output:\n%s", out)
+		}
+	}
+
+	enumStyle = "bogus"
+	if err := checkEnumStyle(); err == nil {
+		t.Error("invalid --enum-style accepted")
+	}
+}
+
+func TestHeaderEnumAliases(t *testing.T) {
+	defer func(b bool) { yang.ParseOptions.AllowEnumAliases = b }(yang.ParseOptions.AllowEnumAliases)
+	yang.ParseOptions.AllowEnumAliases = true
+
+	entries := compileString(t, "alias.yang", `
+module alias {
+  namespace "urn:alias";
+  prefix "a";
+
+  container paint {
+    leaf color {
+      type enumeration {
+        enum red { value 1; }
+        enum blue { value 2; }
+        enum crimson { value 1; }
+      }
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	want := `  enum Color {
+    Color_RED = 1;
+    Color_CRIMSON = 1; // alias of Color_RED
+    Color_BLUE = 2;
+  };
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestHeaderUnique(t *testing.T) {
+	entries := compileString(t, "unique.yang", `
+module unique {
+  namespace "urn:unique";
+  prefix "u";
+
+  list server {
+    key name;
+    unique "ip port";
+    leaf name { type string; }
+    leaf ip { type string; }
+    leaf port { type uint16; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	if want := "struct Server {\n  // unique: ip port\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestHeaderBaseTypeOnly(t *testing.T) {
+	defer func(b bool) { baseTypeOnly = b }(baseTypeOnly)
+	baseTypeOnly = true
+
+	entries := compileString(t, "base.yang", `
+module base {
+  namespace "urn:base";
+  prefix "b";
+
+  typedef percent {
+    type uint8 { range "0..100"; }
+  }
+
+  container system {
+    leaf load { type percent; }
+  }
+}
+`)
+	for _, gen := range []func(io.Writer, []*yang.Entry){doHeader, doType} {
+		var buf bytes.Buffer
+		gen(&buf, entries)
+		if strings.Contains(buf.String(), "typedef") {
+			t.Errorf("found typedef in output:\n%s", buf.String())
+		}
+	}
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	if want := "uint32 load = 1;"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in output:\n%s", want, buf.String())
+	}
+}
+
+func TestHeaderEnumsFile(t *testing.T) {
+	defer func(s string) { enumsFile = s }(enumsFile)
+	dir, err := ioutil.TempDir("", "yangc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	enumsFile = filepath.Join(dir, "enums.h")
+
+	var buf bytes.Buffer
+	entries := compileString(t, "enums.yang", `
+module enums {
+  namespace "urn:enums";
+  prefix "e";
+
+  container peer {
+    leaf state {
+      type enumeration {
+        enum up;
+        enum down;
+      }
+    }
+    leaf address {
+      type union {
+        type string;
+        type uint32;
+      }
+    }
+  }
+}
+`)
+	if err := writeHeader(&buf, entries, doHeader); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(enumsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, enums := buf.String(), string(data)
+	for _, want := range []string{
+		"\nenum State {\n  State_UP = 0;\n",
+		"\nenum AddressKind {\n",
+	} {
+		if !strings.Contains(enums, want) {
+			t.Errorf("missing %q in enums file:\n%s", want, enums)
+		}
+	}
+	for _, want := range []string{
+		fmt.Sprintf("#include %q\n", enumsFile),
+		"enum AddressKind address_kind = 1;",
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("missing %q in header:\n%s", want, header)
+		}
+	}
+	if strings.Contains(header, "State_UP") || strings.Contains(header, "AddressKind {") {
+		t.Errorf("found enum members in header:\n%s", header)
+	}
+}
+
+func TestHeaderMultiLineDescription(t *testing.T) {
+	entries := compileString(t, "multi.yang", `
+module multi {
+  namespace "urn:multi";
+  prefix "m";
+
+  container system {
+    description "System settings.
+      Changes need a restart.";
+    leaf name {
+      type string;
+      description "Host name.
+        Not the domain.";
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	for _, want := range []string{
+		"\n// System settings.\n// Changes need a restart.\nstruct System {\n",
+		"\n  // Host name.\n  // Not the domain.\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestHeaderDoxygen(t *testing.T) {
+	defer func(s string) { commentStyle = s }(commentStyle)
+	commentStyle = "doxygen"
+
+	entries := compileString(t, "doxygen.yang", `
+module doxygen {
+  namespace "urn:doxygen";
+  prefix "d";
+
+  container system {
+    description "System settings. Applied at boot.
+      Changes need a restart.";
+    leaf name {
+      type string;
+      description "Host name.";
+    }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	for _, want := range []string{
+		"\n/**\n * @brief System settings.\n *\n * Applied at boot.\n * Changes need a restart.\n */\nstruct System {\n",
+		"\n  /**\n   * @brief Host name.\n   */\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "// System") || strings.Contains(buf.String(), "// Host") {
+		t.Errorf("found line comment in output:\n%s", buf.String())
+	}
+
+	commentStyle = "bogus"
+	if err := checkCommentStyle(); err == nil {
+		t.Error("invalid --comment-style accepted")
+	}
+}
+
+func TestHeaderRPCWithoutInputOutput(t *testing.T) {
+	entries := compileString(t, "rpc.yang", `
+module rpc {
+  namespace "urn:rpc";
+  prefix "r";
+
+  rpc ping;
+  rpc reset {
+    input { leaf force { type boolean; } }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doHeader(&buf, entries)
+	for _, want := range []string{
+		"struct PingRequest {\n}\n",
+		"struct PingResponse {\n}\n",
+		"struct ResetRequest {\nbool force = 1;\n}\n",
+		"struct ResetResponse {\n}\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in header:\n%s", want, buf.String())
+		}
+	}
+	// The entries are shared with the other generators.
+	if got := entries[0].Dir["reset"].RPC.Input.Name; got != "input" {
+		t.Errorf("input of reset renamed to %s", got)
+	}
+
+	buf.Reset()
+	doProto(&buf, entries)
+	if want := "rpc Ping (Empty) returns (Empty);"; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in proto:\n%s", want, buf.String())
+	}
+}
+
+func TestHeaderForwardDeclarations(t *testing.T) {
+	entries := compileString(t, "forward.yang", `
+module forward {
+  namespace "urn:forward";
+  prefix "f";
+
+  container node {
+    leaf name { type string; }
+    leaf peer { type leafref { path "/link/name"; } }
+  }
+  container link {
+    leaf name { type string; }
+    leaf node { type leafref { path "/node/name"; } }
+    container stats { leaf errors { type uint32; } }
+  }
+}
+`)
+	for name, gen := range map[string]func(io.Writer, []*yang.Entry){"header": doHeader, "table": doTable} {
+		var buf bytes.Buffer
+		gen(&buf, entries)
+		out := buf.String()
+		decls := strings.Index(out, "struct Node;\nstruct Link;\nstruct Stats;\n")
+		if decls < 0 {
+			t.Errorf("%s: missing forward declarations in output:\n%s", name, out)
+			continue
+		}
+		for _, def := range []string{"struct Link {", "struct Stats {", "struct Node {"} {
+			if i := strings.Index(out, def); i < decls {
+				t.Errorf("%s: %q not after the forward declarations:\n%s", name, def, out)
+			}
+		}
+	}
+}
+
+func TestHeaderFileScopeCollision(t *testing.T) {
+	defer func(c map[string]int, w io.Writer) {
+		diagnosticCounts, diagOut = c, w
+	}(diagnosticCounts, diagOut)
+	diagnosticCounts = map[string]int{}
+	var errs bytes.Buffer
+	diagOut = &errs
+
+	entries := compileStrings(t, map[string]string{
+		"alpha": `
+module alpha {
+  namespace "urn:alpha";
+  prefix "a";
+
+  container link-state {
+    leaf up { type boolean; }
+  }
+}
+`,
+		"beta": `
+module beta {
+  namespace "urn:beta";
+  prefix "b";
+
+  container link_state {
+    leaf mode {
+      type enumeration {
+        enum fast;
+        enum slow;
+      }
+    }
+  }
+}
+`,
+	})
+	doHeader(ioutil.Discard, entries)
+	if n := diagnosticCounts["error"]; n != 1 {
+		t.Fatalf("got %d errors, want 1:\n%s", n, errs.String())
+	}
+	for _, want := range []string{"LinkState", "/alpha/link-state", "/beta/link_state"} {
+		if !strings.Contains(errs.String(), want) {
+			t.Errorf("missing %q in: %s", want, errs.String())
+		}
+	}
+
+	// Written to separate files the modules do not collide.
+	diagnosticCounts = map[string]int{}
+	errs.Reset()
+	for _, e := range entries {
+		doHeader(ioutil.Discard, []*yang.Entry{e})
+	}
+	if n := diagnosticCounts["error"]; n != 0 {
+		t.Errorf("got %d errors for separate files, want 0:\n%s", n, errs.String())
+	}
+}
+
+func TestHeaderStableOrder(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	entries := compileString(t, "many.yang", `
+module many {
+  namespace "urn:many";
+  prefix "m";
+
+  container zulu { leaf a { type string; } }
+  container alpha { leaf b { type int32; } }
+  container mike { leaf c { type boolean; } }
+  container echo { leaf d { type string; } }
+  container kilo { leaf e { type uint8; } }
+  leaf yankee {
+    type enumeration {
+      enum on;
+      enum off;
+    }
+  }
+  leaf bravo {
+    type enumeration {
+      enum up;
+      enum down;
+    }
+  }
+}
+`)
+	for _, gen := range []struct {
+		name string
+		f    func(io.Writer, []*yang.Entry)
+	}{
+		{"header", doHeader},
+		{"type", doType},
+		{"table", doTable},
+	} {
+		var first bytes.Buffer
+		gen.f(&first, entries)
+		for i := 0; i < 20; i++ {
+			var buf bytes.Buffer
+			gen.f(&buf, entries)
+			if !bytes.Equal(buf.Bytes(), first.Bytes()) {
+				t.Fatalf("%s: run %d differs:\n%s\nfirst:\n%s", gen.name, i, buf.String(), first.String())
+			}
+		}
+	}
+}
+
+func TestHeaderBinaryLength(t *testing.T) {
+	entries := compileString(t, "binary.yang", `
+module binary {
+  namespace "urn:binary";
+  prefix "b";
+
+  container key {
+    leaf digest { type binary { length 16; } }
+    leaf data { type binary { length "1..64"; } }
+    leaf blob { type binary; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doTable(&buf, entries)
+	out := buf.String()
+	for _, want := range []string{
+		"uint8_t digest[16] = ",
+		"bytes data = 2; // length=1..64\n",
+		"bytes blob = 3;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestHeaderStripComments(t *testing.T) {
+	defer func(s string, b bool) { commentStyle, stripComments = s, b }(commentStyle, stripComments)
+	commentStyle = "doxygen"
+	stripComments = true
+
+	entries := compileString(t, "strip.yang", `
+module strip {
+  namespace "urn:strip";
+  prefix "s";
+
+  container logs {
+    description 'Logs matching /var/*/log. Ends a line in \
+      a backslash.';
+    leaf path { type string; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doTable(&buf, entries)
+	out := buf.String()
+	begin := strings.Index(out, "/**\n")
+	end := strings.Index(out, "\n */\n")
+	if begin < 0 || end < begin {
+		t.Fatalf("no doxygen block in:\n%s", out)
+	}
+	block := out[begin+len("/**") : end]
+	if strings.Contains(block, "*/") || strings.Contains(block, "/*") {
+		t.Errorf("comment ended early:\n%s", out)
+	}
+	for _, l := range strings.Split(block, "\n") {
+		if strings.HasSuffix(l, "\\") {
+			t.Errorf("line continues the comment: %q", l)
+		}
+	}
+	if !strings.Contains(block, "/var/ * /log") {
+		t.Errorf("description not kept:\n%s", out)
+	}
+}
+
+func TestTypedefStripComments(t *testing.T) {
+	defer func(b bool) { stripComments = b }(stripComments)
+	stripComments = true
+
+	entries := compileString(t, "td.yang", `
+module td {
+  namespace "urn:td";
+  prefix "t";
+
+  typedef mode {
+    type enumeration {
+      enum fast {
+        description 'Goes fast.
+          Really fast \  ';
+      }
+      enum slow;
+    }
+    description 'The mode.
+      Matches */ here \  ';
+  }
+  leaf m { type mode; }
+}
+`)
+	var buf bytes.Buffer
+	printNodeTypedef(&buf, entries[0].Node.(*yang.Module).Typedef[0])
+	out := buf.String()
+	if strings.Contains(out, "*/") {
+		t.Errorf("comment ended early:\n%s", out)
+	}
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasSuffix(strings.TrimSpace(l), "\\") {
+			t.Errorf("line continues the comment: %q", l)
+		}
+		if strings.Contains(l, "Matches") && !strings.HasPrefix(strings.TrimSpace(l), "//") {
+			t.Errorf("description line not commented: %q", l)
+		}
+	}
+	if !strings.Contains(out, "fast =  // Goes fast. Really fast\n") {
+		t.Errorf("enum description not on one line:\n%s", out)
+	}
+
+	// Without the flag descriptions are written as is.
+	stripComments = false
+	buf.Reset()
+	printNodeTypedef(&buf, entries[0].Node.(*yang.Module).Typedef[0])
+	if out := buf.String(); !strings.Contains(out, "fast =  // Goes fast.\n") || !strings.Contains(out, "Really fast \\  \n") {
+		t.Errorf("description changed without --strip-comments-from-descriptions:\n%s", out)
+	}
+}
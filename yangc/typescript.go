@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/indent"
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var typescriptCmd = &cobra.Command{
+		Use:   "typescript",
+		Short: "yangc with TypeScript interface format",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			doTypeScript(os.Stdout, entries)
+		},
+	}
+	mainCmd.AddCommand(typescriptCmd)
+}
+
+// kind2ts maps base yang types to TypeScript types of their JSON encoding
+// (RFC 7951).  64 bit numbers and decimal64 are encoded as strings.
+var kind2ts = map[yang.TypeKind]string{
+	yang.Yint8:   "number",
+	yang.Yint16:  "number",
+	yang.Yint32:  "number",
+	yang.Yint64:  "string",
+	yang.Yuint8:  "number",
+	yang.Yuint16: "number",
+	yang.Yuint32: "number",
+	yang.Yuint64: "string",
+
+	yang.Ybinary:             "string",  // base64 encoded
+	yang.Ybits:               "string",  // space separated bit names
+	yang.Ybool:               "boolean", // true or false
+	yang.Ydecimal64:          "string",  // signed decimal number
+	yang.Yempty:              "[null]",  // value is its presense
+	yang.Yenum:               "enum",    // handled inline
+	yang.Yidentityref:        "string",  // reference to abstract identity
+	yang.YinstanceIdentifier: "string",  // reference of a data tree node
+	yang.Yleafref:            "string",  // reference to a leaf instance
+	yang.Ystring:             "string",  // human readable string
+	yang.Yunion:              "union",   // handled inline
+}
+
+// doTypeScript writes a TypeScript interface for every container and list
+// in entries to w.
+func doTypeScript(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		if e == nil || len(e.Dir) == 0 {
+			continue // skip modules that have nothing in them
+		}
+		pf := &protofile{
+			fixedNames: map[string]string{},
+			messages:   map[string]*messageInfo{},
+		}
+		pf.printHeader(w, e, false)
+		pf.printInterfaces(w, e)
+	}
+}
+
+// isDirectory returns true if e is a container or list rather than a leaf.
+func isDirectory(e *yang.Entry) bool {
+	return len(e.Dir) > 0 || e.Type == nil
+}
+
+// isChoice returns true if e is a choice or case.  They do not appear in
+// the data tree, their children take their place.
+func isChoice(e *yang.Entry) bool {
+	return e.Kind == yang.ChoiceEntry || e.Kind == yang.CaseEntry
+}
+
+// printInterfaces writes an interface for each container and list below e.
+func (pf *protofile) printInterfaces(w io.Writer, e *yang.Entry) {
+	for _, se := range children(e) {
+		if !isDirectory(se) {
+			continue
+		}
+		if !isChoice(se) {
+			fmt.Fprintln(w)
+			pf.printInterface(w, se)
+		}
+		pf.printInterfaces(w, se)
+	}
+}
+
+// printInterface writes the interface for e to w.
+func (pf *protofile) printInterface(w io.Writer, e *yang.Entry) {
+	if e.Description != "" {
+		fmt.Fprintln(indent.NewWriter(w, "// "), e.Description)
+	}
+	fmt.Fprintf(w, "export interface %s {\n", pf.qualifiedName(e)) // matching brace }
+	pf.printMembers(indent.NewWriter(w, indentString), e, false)
+	// { to match the brace below to keep brace matching working
+	fmt.Fprintln(w, "}")
+}
+
+// printMembers writes the members of the interface for e to w.  The members
+// of a choice are always optional.
+func (pf *protofile) printMembers(w io.Writer, e *yang.Entry, inChoice bool) {
+	for _, se := range children(e) {
+		if isChoice(se) {
+			pf.printMembers(w, se, true)
+			continue
+		}
+		var typ string
+		if isDirectory(se) {
+			typ = pf.qualifiedName(se)
+		} else {
+			typ = tsType(se, fieldType(se))
+		}
+		if se.ListAttr != nil {
+			if strings.Contains(typ, " | ") {
+				typ = "(" + typ + ")"
+			}
+			typ += "[]"
+		}
+		opt := "?"
+		if !inChoice && (isKey(se) || isMandatory(se)) {
+			opt = ""
+		}
+		if isDeprecated(se) {
+			fmt.Fprintln(w, "/** @deprecated */")
+		}
+		fmt.Fprintf(w, "%s%s: %s;\n", tsName(se.Name), opt, typ)
+	}
+}
+
+// tsType returns the TypeScript type for values of type t, the type of se.
+// Enumerations and unions become unions of their members.
+func tsType(se *yang.Entry, t *yang.YangType) string {
+	if t == nil {
+		return "unknown"
+	}
+	switch t.Kind {
+	case yang.Yenum:
+		var names []string
+		for _, n := range t.Enum.Names() {
+			names = append(names, strconv.Quote(n))
+		}
+		return strings.Join(names, " | ")
+	case yang.Yunion:
+		var types []string
+		seen := map[string]bool{}
+		for _, ut := range t.Type {
+			st := tsType(se, ut)
+			if !seen[st] {
+				seen[st] = true
+				types = append(types, st)
+			}
+		}
+		return strings.Join(types, " | ")
+	}
+	ts, _ := lookupKind(kind2ts, "unknown", se, t.Kind)
+	return ts
+}
+
+// tsName returns name as a TypeScript property name, quoted if it is not a
+// valid identifier.
+func tsName(name string) string {
+	if name != "" && yang.SanitizeIdentifier(name) == name && (name[0] < '0' || name[0] > '9') {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// isMandatory returns true if e is a leaf with "mandatory true".
+func isMandatory(e *yang.Entry) bool {
+	l, ok := e.Node.(*yang.Leaf)
+	return ok && l.Mandatory != nil && l.Mandatory.Name == "true"
+}
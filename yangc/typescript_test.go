@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+func TestTypeScript(t *testing.T) {
+	e := compileString(t, "ts.yang", `
+module ts {
+  namespace "urn:ts";
+  prefix "ts";
+
+  container system {
+    leaf host-name { type string; mandatory true; }
+    leaf state {
+      type enumeration {
+        enum up;
+        enum down;
+      }
+    }
+    leaf-list dns { type string; }
+    list user {
+      key name;
+      leaf name { type string; }
+      leaf uid { type uint32; }
+    }
+  }
+}
+`)[0]
+	var buf bytes.Buffer
+	doTypeScript(&buf, []*yang.Entry{e})
+	for _, want := range []string{
+		"\nexport interface System {\n",
+		"\n  \"host-name\": string;\n",
+		"\n  state?: \"down\" | \"up\";\n",
+		"\n  dns?: string[];\n",
+		"\n  user?: SystemUser[];\n",
+		"\nexport interface SystemUser {\n  name: string;\n  uid?: number;\n}\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// fetchTimeout limits how long fetching a module from a URL may take.
+var fetchTimeout time.Duration
+
+func init() {
+	mainCmd.PersistentFlags().DurationVar(&fetchTimeout, "timeout", 30*time.Second, "timeout when fetching a module from an http or https URL")
+}
+
+// isURL reports whether name is an http or https URL rather than a file.
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// fetchURL returns the contents of the module at url.  It is an error for
+// the server to respond with anything other than 200 OK.
+func fetchURL(url string) (string, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", url, err)
+	}
+	return string(data), nil
+}
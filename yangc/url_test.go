@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/remote.yang" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `
+module remote {
+  namespace "urn:remote";
+  prefix "r";
+  container box { leaf size { type uint32; } }
+}
+`)
+	}))
+	defer ts.Close()
+
+	if !isURL(ts.URL) {
+		t.Fatalf("%s not detected as a URL", ts.URL)
+	}
+	entries, errs := compile(ts.URL + "/remote.yang")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(entries) != 1 || entries[0].Name != "remote" {
+		t.Fatalf("got %d entries, want module remote", len(entries))
+	}
+	if entries[0].Dir["box"] == nil {
+		t.Errorf("container box missing from fetched module")
+	}
+
+	if _, err := fetchURL(ts.URL + "/missing.yang"); err == nil {
+		t.Errorf("no error for a missing module")
+	}
+}
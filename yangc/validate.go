@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/paranpen/yangc/pkg/yang"
+	"github.com/spf13/cobra"
+)
+
+// requireDescriptions reports nodes without a description when validating.
+var requireDescriptions bool
+
+func init() {
+	var validateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "check the modules compile and pass the selected lint rules",
+		Run: func(cmd *cobra.Command, args []string) {
+			entries := doCompile(yangFileName)
+			exitIfError(doValidate(entries))
+		},
+	}
+	validateCmd.Flags().BoolVar(&requireDescriptions, "require-descriptions", false, "report data nodes and typedefs without a description")
+	mainCmd.AddCommand(validateCmd)
+}
+
+// doValidate returns the lint errors found in entries.  The must and when
+// expressions, list keys and leafref paths are always checked, the other
+// rules only when selected.
+func doValidate(entries []*yang.Entry) []error {
+	var errs []error
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		errs = append(errs, xpathErrors(e)...)
+		errs = append(errs, keyErrors(e)...)
+		errs = append(errs, leafrefErrors(e)...)
+		if requireDescriptions {
+			for _, se := range sortedDir(e) {
+				errs = append(errs, descriptionErrors(se)...)
+			}
+		}
+	}
+	return errs
+}
+
+// descriptionErrors returns an error for e and each of its descendants that
+// is a data node, RPC or typedef without a description.  Choices, cases and
+// the input and output of RPCs are not required to have one.
+func descriptionErrors(e *yang.Entry) []error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	if e.Description == "" && !isChoice(e) {
+		errs = append(errs, fmt.Errorf("%s: %s %s has no description", yang.Source(e.Node), kindName(e), e.FullPath()))
+	}
+	if e.RPC != nil {
+		for _, p := range []*yang.Entry{e.RPC.Input, e.RPC.Output} {
+			if p != nil {
+				for _, se := range sortedDir(p) {
+					errs = append(errs, descriptionErrors(se)...)
+				}
+			}
+		}
+	}
+	for _, se := range sortedDir(e) {
+		errs = append(errs, descriptionErrors(se)...)
+	}
+	return errs
+}
+
+// xpathErrors returns an error for each must or when expression in e and its
+// descendants that is not a well formed XPath expression.
+func xpathErrors(e *yang.Entry) []error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	if w := nodeValue(e, "When"); w != "" {
+		if err := checkXPath(w); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid when %q: %v", yang.Source(e.Node), w, err))
+		}
+	}
+	for _, m := range musts(e) {
+		if err := checkXPath(m.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid must %q: %v", yang.Source(m), m.Name, err))
+		}
+	}
+	if e.RPC != nil {
+		errs = append(errs, xpathErrors(e.RPC.Input)...)
+		errs = append(errs, xpathErrors(e.RPC.Output)...)
+	}
+	for _, se := range sortedDir(e) {
+		errs = append(errs, xpathErrors(se)...)
+	}
+	return errs
+}
+
+// keyErrors returns an error for each key of a list in e and its
+// descendants that is not a leaf or is a leaf of type empty, neither of
+// which can identify a list entry.
+func keyErrors(e *yang.Entry) []error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	for _, name := range listKeys(e) {
+		k := e.Dir[name]
+		switch {
+		case k == nil:
+			// A missing key is reported when processing the module.
+		case k.Kind != yang.LeafEntry:
+			errs = append(errs, fmt.Errorf("%s: key %s of list %s is %s, not a leaf", yang.Source(k.Node), name, e.FullPath(), kindName(k)))
+		case k.Type != nil && k.Type.Kind == yang.Yempty:
+			errs = append(errs, fmt.Errorf("%s: key %s of list %s has type empty", yang.Source(k.Node), name, e.FullPath()))
+		}
+	}
+	if e.RPC != nil {
+		errs = append(errs, keyErrors(e.RPC.Input)...)
+		errs = append(errs, keyErrors(e.RPC.Output)...)
+	}
+	for _, se := range sortedDir(e) {
+		errs = append(errs, keyErrors(se)...)
+	}
+	return errs
+}
+
+// leafrefErrors returns an error for each leafref in e and its descendants
+// whose path does not lead to a leaf.
+func leafrefErrors(e *yang.Entry) []error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	if t := e.Type; t != nil && t.Kind == yang.Yleafref {
+		target := e.Find(stripPredicates(t.Path))
+		switch {
+		case target == nil:
+			errs = append(errs, fmt.Errorf("%s: leafref %s: path %s not found", yang.Source(e.Node), e.FullPath(), t.Path))
+		case target.Kind != yang.LeafEntry:
+			errs = append(errs, fmt.Errorf("%s: leafref %s: path %s is %s, not a leaf", yang.Source(e.Node), e.FullPath(), t.Path, kindName(target)))
+		}
+	}
+	if e.RPC != nil {
+		errs = append(errs, leafrefErrors(e.RPC.Input)...)
+		errs = append(errs, leafrefErrors(e.RPC.Output)...)
+	}
+	for _, se := range sortedDir(e) {
+		errs = append(errs, leafrefErrors(se)...)
+	}
+	return errs
+}
+
+// musts returns the must statements of the node e was derived from.
+func musts(e *yang.Entry) []*yang.Must {
+	if e.Node == nil {
+		return nil
+	}
+	v := reflect.ValueOf(e.Node)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	f := v.Elem().FieldByName("Must")
+	if !f.IsValid() {
+		return nil
+	}
+	ms, _ := f.Interface().([]*yang.Must)
+	return ms
+}
+
+// kindName returns the YANG statement that defined e, such as "leaf".
+func kindName(e *yang.Entry) string {
+	if e.Node == nil {
+		return e.Kind.String()
+	}
+	return e.Node.Kind()
+}
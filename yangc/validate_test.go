@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequireDescriptions(t *testing.T) {
+	defer func(b bool) { requireDescriptions = b }(requireDescriptions)
+
+	entries := compileString(t, "doc.yang", `
+module doc {
+  namespace "urn:doc";
+  prefix "d";
+
+  typedef percent {
+    type uint8 { range "0..100"; }
+    description "A percentage.";
+  }
+  container system {
+    description "System settings.";
+    leaf hostname {
+      type string;
+      description "The host name.";
+    }
+    leaf load { type percent; }
+    choice mode {
+      leaf fast { type empty; description "Go fast."; }
+    }
+  }
+}
+`)
+	requireDescriptions = false
+	if errs := doValidate(entries); len(errs) != 0 {
+		t.Errorf("got errors without --require-descriptions: %v", errs)
+	}
+
+	requireDescriptions = true
+	errs := doValidate(entries)
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors %v, want 1", len(errs), errs)
+	}
+	if got, want := errs[0].Error(), "leaf /doc:system/load has no description"; !strings.HasSuffix(got, want) {
+		t.Errorf("got %q, want suffix %q", got, want)
+	}
+}
+
+func TestKeyTypes(t *testing.T) {
+	entries := compileString(t, "keys.yang", `
+module keys {
+  namespace "urn:keys";
+  prefix "k";
+
+  container flags {
+    list flag {
+      key "set";
+      leaf set { type empty; }
+    }
+    list named {
+      key "name";
+      leaf name { type string; }
+    }
+  }
+}
+`)
+	errs := doValidate(entries)
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors %v, want 1", len(errs), errs)
+	}
+	if got, want := errs[0].Error(), "key set of list /keys:flags/flag has type empty"; !strings.HasSuffix(got, want) {
+		t.Errorf("got %q, want suffix %q", got, want)
+	}
+}
+
+func TestLeafrefPaths(t *testing.T) {
+	entries := compileString(t, "refs.yang", `
+module refs {
+  namespace "urn:refs";
+  prefix "r";
+
+  container interfaces {
+    list interface {
+      key "name";
+      leaf name { type string; }
+    }
+  }
+  container routes {
+    leaf via { type leafref { path "/r:interfaces/r:interface/r:name"; } }
+    leaf gone { type leafref { path "/r:interfaces/r:interface/r:mtu"; } }
+    leaf list { type leafref { path "/r:interfaces/r:interface"; } }
+  }
+}
+`)
+	errs := doValidate(entries)
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	for _, want := range []string{
+		"leafref /refs:routes/gone: path /r:interfaces/r:interface/r:mtu not found",
+		"leafref /refs:routes/list: path /r:interfaces/r:interface is list, not a leaf",
+	} {
+		found := false
+		for _, g := range got {
+			found = found || strings.HasSuffix(g, want)
+		}
+		if !found {
+			t.Errorf("missing %q in %q", want, got)
+		}
+	}
+	if len(errs) != 2 {
+		t.Errorf("got %d errors %q, want 2", len(errs), got)
+	}
+}
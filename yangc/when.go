@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// groupWhen groups the fields of C structs by their when expression, each
+// group in an #if block naming the condition.
+var groupWhen bool
+
+// groupByWhen returns nodes with those without a when expression first,
+// followed by the others grouped by their when expression in the order the
+// expressions first appear.  The order within a group is kept.
+func groupByWhen(nodes []*yang.Entry) []*yang.Entry {
+	var conds []string
+	groups := map[string][]*yang.Entry{}
+	for _, e := range nodes {
+		c := nodeValue(e, "When")
+		if _, ok := groups[c]; !ok && c != "" {
+			conds = append(conds, c)
+		}
+		groups[c] = append(groups[c], e)
+	}
+	grouped := groups[""]
+	for _, c := range conds {
+		grouped = append(grouped, groups[c]...)
+	}
+	return grouped
+}
+
+// switchWhen writes to w the end of the #if block of the condition cur and
+// the start of the block of next, unless they are the same, and returns
+// next.  Either may be "", for no condition.
+func switchWhen(w io.Writer, cur, next string) string {
+	if cur == next {
+		return cur
+	}
+	if cur != "" {
+		fmt.Fprintf(w, "#endif /* when: %s */\n", whenComment(cur))
+	}
+	if next != "" {
+		fmt.Fprintf(w, "#if 1 /* when: %s */\n", whenComment(next))
+	}
+	return next
+}
+
+// whenComment returns the when expression cond made safe to place in a C
+// comment.
+func whenComment(cond string) string {
+	return sanitizeComment(strings.Join(strings.Fields(cond), " "))
+}
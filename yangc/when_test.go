@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHeaderGroupWhen(t *testing.T) {
+	defer func(b bool) { groupWhen = b }(groupWhen)
+	groupWhen = true
+
+	entries := compileString(t, "when.yang", `
+module when {
+  namespace "urn:when";
+  prefix "w";
+
+  container port {
+    leaf type { type string; }
+    leaf speed {
+      when "../type = 'ethernet'";
+      type uint32;
+    }
+    leaf channel {
+      when "../type = 'wireless'";
+      type uint8;
+    }
+    leaf duplex {
+      when "../type = 'ethernet'";
+      type boolean;
+    }
+    leaf name { type string; }
+  }
+}
+`)
+	var buf bytes.Buffer
+	doTable(&buf, entries)
+	// The tags are those of the declaration order.
+	want := `string type = 1;
+string name = 5;
+#if 1 /* when: ../type = 'ethernet' */
+uint32 speed = 2;
+bool duplex = 4;
+#endif /* when: ../type = 'ethernet' */
+#if 1 /* when: ../type = 'wireless' */
+uint32 channel = 3;
+#endif /* when: ../type = 'wireless' */
+}
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	groupWhen = false
+	buf.Reset()
+	doTable(&buf, entries)
+	for _, field := range []string{"string type = 1;", "uint32 speed = 2;", "uint32 channel = 3;", "bool duplex = 4;", "string name = 5;"} {
+		if !strings.Contains(buf.String(), field) {
+			t.Errorf("missing %q without --group-when:\n%s", field, buf.String())
+		}
+	}
+}
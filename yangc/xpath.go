@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// xpathAxes are the axis names defined by XPath 1.0.
+var xpathAxes = map[string]bool{
+	"ancestor":           true,
+	"ancestor-or-self":   true,
+	"attribute":          true,
+	"child":              true,
+	"descendant":         true,
+	"descendant-or-self": true,
+	"following":          true,
+	"following-sibling":  true,
+	"namespace":          true,
+	"parent":             true,
+	"preceding":          true,
+	"preceding-sibling":  true,
+	"self":               true,
+}
+
+// xpathComparisons are the binary operators that need an operand on both
+// sides.  Operators that may also start an expression, such as - and /,
+// are not included.
+var xpathComparisons = map[string]bool{
+	"=":  true,
+	"!=": true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+	">=": true,
+	"|":  true,
+}
+
+// xpathTokens splits the XPath expression s into tokens.  Names, including
+// prefixed names, numbers, string literals and operators are each a token.
+func xpathTokens(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			n := strings.IndexByte(s[i+1:], c)
+			if n < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, s[i:i+n+2])
+			i += n + 2
+		case strings.HasPrefix(s[i:], "::"), strings.HasPrefix(s[i:], "//"),
+			strings.HasPrefix(s[i:], ".."), strings.HasPrefix(s[i:], "!="),
+			strings.HasPrefix(s[i:], "<="), strings.HasPrefix(s[i:], ">="):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.IndexByte("()[]/@,|+-=<>*$", c) >= 0:
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case c == '.' || unicode.IsDigit(rune(c)):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case isNameStart(c):
+			j := i + 1
+			for j < len(s) && (isNameChar(s[j]) || s[j] == ':' && j+1 < len(s) && s[j+1] != ':' && isNameStart(s[j+1])) {
+				j++
+			}
+			if strings.HasPrefix(s[j:], ":*") {
+				j += 2 // prefix:*
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c == '-' || c == '.' || c >= '0' && c <= '9'
+}
+
+// checkXPath returns an error if the XPath expression s is lexically
+// malformed: it has an unterminated string, unbalanced parentheses or
+// brackets, an unknown axis, or a comparison missing an operand.  The
+// expression is not evaluated.
+func checkXPath(s string) error {
+	tokens, err := xpathTokens(s)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("empty expression")
+	}
+	var open []string
+	prev := ""
+	for i, t := range tokens {
+		switch t {
+		case "(", "[":
+			open = append(open, t)
+		case ")", "]":
+			want := "("
+			if t == "]" {
+				want = "["
+			}
+			if len(open) == 0 || open[len(open)-1] != want {
+				return fmt.Errorf("unbalanced %q", t)
+			}
+			open = open[:len(open)-1]
+		case "::":
+			if !xpathAxes[prev] {
+				return fmt.Errorf("unknown axis %q", prev)
+			}
+		}
+		if xpathComparisons[t] {
+			next := ""
+			if i+1 < len(tokens) {
+				next = tokens[i+1]
+			}
+			if noLeftOperand(prev) || noRightOperand(next) {
+				return fmt.Errorf("missing operand for %q", t)
+			}
+		}
+		prev = t
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("unbalanced %q", open[len(open)-1])
+	}
+	return nil
+}
+
+// noLeftOperand returns true if t, the token before a binary operator,
+// cannot end its left operand.
+func noLeftOperand(t string) bool {
+	return t == "" || t == "(" || t == "[" || t == "," || xpathComparisons[t]
+}
+
+// noRightOperand returns true if t, the token after a binary operator,
+// cannot start its right operand.
+func noRightOperand(t string) bool {
+	return t == "" || t == ")" || t == "]" || t == "," || xpathComparisons[t]
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckXPath(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		err string
+	}{
+		{in: "../enabled = 'true'"},
+		{in: "count(if:interface[if:type = 'eth']) <= 16"},
+		{in: "not(ancestor::system/clock) and /sys:*"},
+		{in: "current()/../name != \"lo\""},
+		{in: "a[ = b", err: `missing operand for "="`},
+		{in: "a[b = c", err: `unbalanced "["`},
+		{in: "count(a))", err: `unbalanced ")"`},
+		{in: "up::node", err: `unknown axis "up"`},
+		{in: "a = 'b", err: "unterminated string"},
+		{in: "a >", err: `missing operand for ">"`},
+		{in: "", err: "empty expression"},
+	} {
+		err := checkXPath(tt.in)
+		switch {
+		case err == nil && tt.err != "":
+			t.Errorf("%q: got no error, want %q", tt.in, tt.err)
+		case err != nil && tt.err == "":
+			t.Errorf("%q: got error %v", tt.in, err)
+		case err != nil && !strings.Contains(err.Error(), tt.err):
+			t.Errorf("%q: got error %v, want %q", tt.in, err, tt.err)
+		}
+	}
+}
+
+func TestValidateXPath(t *testing.T) {
+	entries := compileString(t, "must.yang", `
+module must {
+  namespace "urn:must";
+  prefix "m";
+
+  container system {
+    leaf a { type string; }
+    leaf b {
+      type string;
+      must "a[ = b";
+    }
+    leaf c {
+      when "../a = 'x'";
+      type string;
+    }
+  }
+}
+`)
+	errs := doValidate(entries)
+	if len(errs) != 1 {
+		t.Fatalf("got errors %v, want 1", errs)
+	}
+	if want := `invalid must "a[ = b": missing operand for "="`; !strings.Contains(errs[0].Error(), want) {
+		t.Errorf("got %v, want %q", errs[0], want)
+	}
+}
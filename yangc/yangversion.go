@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/paranpen/yangc/pkg/yang"
+)
+
+// strict rejects YANG 1.1 constructs used in modules that declare (or
+// default to) yang-version 1.
+var strict bool
+
+func init() {
+	mainCmd.PersistentFlags().BoolVar(&strict, "strict", false, "reject YANG 1.1 constructs in YANG 1.0 modules")
+}
+
+// yangVersion returns the yang-version declared by the module or submodule
+// that defines n.  A module without a yang-version statement is version 1.
+func yangVersion(n yang.Node) string {
+	m := yang.RootNode(n)
+	if m == nil || m.YangVersion == nil {
+		return "1"
+	}
+	if v := m.YangVersion.Name; v != "1.0" {
+		return v
+	}
+	return "1"
+}
+
+// versionErrors returns an error for every YANG 1.1 construct found in e and
+// its descendants that is defined in a YANG 1.0 module.
+func versionErrors(e *yang.Entry) []error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	if e.Node != nil && yangVersion(e.Node) == "1" {
+		var construct string
+		switch {
+		case e.Kind == yang.AnyDataEntry:
+			construct = "anydata"
+		case e.ListAttr != nil && len(e.Defaults) > 1:
+			construct = "multiple leaf-list defaults"
+		}
+		if construct != "" {
+			errs = append(errs, fmt.Errorf("%s: %s requires yang-version 1.1, module %s is yang-version 1", yang.Source(e.Node), construct, yang.RootNode(e.Node).Name))
+		}
+	}
+	if e.RPC != nil {
+		errs = append(errs, versionErrors(e.RPC.Input)...)
+		errs = append(errs, versionErrors(e.RPC.Output)...)
+	}
+	for _, se := range e.Dir {
+		errs = append(errs, versionErrors(se)...)
+	}
+	return errs
+}
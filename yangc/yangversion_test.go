@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersionErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		version string
+		body    string
+		err     string
+	}{
+		{
+			name: "anydata in 1.0",
+			body: "anydata config-data;",
+			err:  "anydata requires yang-version 1.1, module versioned is yang-version 1",
+		},
+		{
+			name:    "anydata in explicit 1",
+			version: `yang-version "1";`,
+			body:    "anydata config-data;",
+			err:     "anydata requires yang-version 1.1",
+		},
+		{
+			name:    "anydata in 1.1",
+			version: "yang-version 1.1;",
+			body:    "anydata config-data;",
+		},
+		{
+			name: "leaf-list defaults in 1.0",
+			body: `leaf-list l { type string; default a; default b; }`,
+			err:  "multiple leaf-list defaults requires yang-version 1.1",
+		},
+		{
+			name: "single leaf-list default in 1.0",
+			body: `leaf-list l { type string; default a; }`,
+		},
+	} {
+		e := compileString(t, "versioned.yang", `
+module versioned {
+  `+tt.version+`
+  namespace "urn:versioned";
+  prefix "v";
+
+  container top {
+    `+tt.body+`
+  }
+}
+`)[0]
+		errs := versionErrors(e)
+		switch {
+		case tt.err == "" && len(errs) > 0:
+			t.Errorf("%s: unexpected errors: %v", tt.name, errs)
+		case tt.err != "" && len(errs) != 1:
+			t.Errorf("%s: got errors %v, want %q", tt.name, errs, tt.err)
+		case tt.err != "" && !strings.Contains(errs[0].Error(), tt.err):
+			t.Errorf("%s: got error %v, want %q", tt.name, errs[0], tt.err)
+		}
+	}
+}